@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveLimit configures `WithAdaptiveLimit`. Every time `limit` limited
+// jobs finish the limit is checked, if the jobs were too slow or errored too
+// much it is decreased (multiplicative) otherwise it is increased (additive)
+type AdaptiveLimit struct {
+	Min int // lowest the limit can go, must be at least 1
+	Max int // highest the limit can go, must be at least Min
+
+	// TargetLatency is the average run time of jobs above which the limit is
+	// decreased. Zero means latency is ignored
+	TargetLatency time.Duration
+	// MaxErrorRate is the fraction (0-1) of jobs that can error before the
+	// limit is decreased. Zero means any error decreases the limit
+	MaxErrorRate float64
+
+	Increase int     // added to the limit when healthy, defaults to 1
+	Decrease float64 // multiplied by the limit when overloaded, defaults to 0.5
+}
+
+// WithAdaptiveLimit adjusts the limit used by `LimitedGo` and `BlLimitedGo`
+// based on the latency and errors of the jobs. The limit passed to
+// `NewControllerWithLimit` is used as the starting limit
+func WithAdaptiveLimit(config AdaptiveLimit) Option {
+	return func(c *Controller) error {
+		if config.Min < 1 || config.Max < config.Min {
+			return ErrInvalidLimit
+		}
+		if config.Increase < 1 {
+			config.Increase = 1
+		}
+		if config.Decrease <= 0 || config.Decrease >= 1 {
+			config.Decrease = 0.5
+		}
+		c.adaptive = &adaptiveLimit{config: config}
+		limit, _, _ := c.limiter.usage()
+		c.limiter.setLimit(min(max(limit, config.Min), config.Max))
+		return nil
+	}
+}
+
+type adaptiveLimit struct {
+	config  AdaptiveLimit
+	mu      sync.Mutex
+	samples int
+	errors  int
+	latency time.Duration
+}
+
+// observe records a finished job and adjusts the limit once enough have finished
+func (a *adaptiveLimit) observe(l *limiter, j *job) {
+	if j.skipped {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.samples++
+	a.latency += j.ended.Sub(j.started)
+	if j.err != nil && j.err != ErrShuttingDown {
+		a.errors++
+	}
+
+	limit, _, _ := l.usage()
+	if a.samples < limit {
+		return
+	}
+
+	newLimit := limit
+	errorRate := float64(a.errors) / float64(a.samples)
+	average := a.latency / time.Duration(a.samples)
+	if errorRate > a.config.MaxErrorRate || (a.config.TargetLatency > 0 && average > a.config.TargetLatency) {
+		newLimit = min(int(float64(limit)*a.config.Decrease), limit-1)
+	} else if l.wasFull() {
+		// only grow if the limit was actually reached, otherwise it doesnt tell us anything
+		newLimit = limit + a.config.Increase
+	}
+	newLimit = min(max(newLimit, a.config.Min), a.config.Max)
+
+	if newLimit != limit {
+		log.Debugf("Adaptive limit %v -> %v (errors %v/%v, average %v)", limit, newLimit, a.errors, a.samples, average)
+		l.setLimit(newLimit)
+	}
+	a.samples = 0
+	a.errors = 0
+	a.latency = 0
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimit(t *testing.T) {
+	t.Run("returns error if bad config", func(t *testing.T) {
+		_, err := NewController(WithAdaptiveLimit(AdaptiveLimit{Min: 4, Max: 2}))
+		if err != ErrInvalidLimit {
+			t.Errorf("expected invalid limit error, got %v", err)
+		}
+	})
+	t.Run("Starting limit is kept between min and max", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(20, WithAdaptiveLimit(AdaptiveLimit{Min: 2, Max: 10}))
+		if c.Limit() != 10 {
+			t.Errorf("expected limit to be 10, got %v", c.Limit())
+		}
+		c.Wait()
+	})
+	t.Run("Decreases when jobs error", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(8, WithAdaptiveLimit(AdaptiveLimit{Min: 2, Max: 8}))
+		for i := 0; i < 40; i++ {
+			c.BlLimitedGo(RunnerFunc(func(rc *Controller) error {
+				return fmt.Errorf("overloaded")
+			}))
+		}
+		c.Wait()
+		if c.Limit() != 2 {
+			t.Errorf("expected limit to drop to 2, got %v", c.Limit())
+		}
+	})
+	t.Run("Decreases when jobs are slow", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(4, WithAdaptiveLimit(AdaptiveLimit{Min: 1, Max: 4, TargetLatency: time.Millisecond}))
+		for i := 0; i < 8; i++ {
+			c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			}))
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+		if c.Limit() >= 4 {
+			t.Errorf("expected limit to drop, got %v", c.Limit())
+		}
+	})
+	t.Run("Increases when healthy and full", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(2, WithWorkerPool(nil), WithAdaptiveLimit(AdaptiveLimit{Min: 1, Max: 6}))
+		for i := 0; i < 60; i++ {
+			c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+				time.Sleep(time.Millisecond)
+				return nil
+			}))
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+		if c.Limit() <= 2 {
+			t.Errorf("expected limit to grow, got %v", c.Limit())
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package runner
+
+// Child returns a new controller for a unit of work inside this one. The
+// child has its own jobs, errors and limit (defaults to the parents limit,
+// name and logger, use `WithLimit`, `WithName` and `WithLogger` to change
+// them) and can be shutdown and waited on without stopping the parent. The
+// childs jobs still count toward the parent: limited jobs take a slot from
+// both limiters (so pausing the parent pauses them too) and they are in the
+// parents `Jobs` and `Stats`. Readiness is shared, so `Ready` from a childs
+// job can be waited on by the parent (and the other way around). While the
+// child is running it counts as a `Go` job on the parent, so `Wait` has to be
+// called on the child. If the parent is shutting down the child is too (and
+// it doesnt have to be waited on).
+// Returns ErrShuttingDown if the parent already is
+func (c *Controller) Child(opts ...Option) (*Controller, error) {
+	return c.child(c.mainCountChan, opts)
+}
+
+// child creates a child controller that is counted on the parent with v
+func (c *Controller) child(v chan bool, opts []Option) (*Controller, error) {
+	child, err := newController(c.Limit(), append([]Option{WithName(c.name), WithLogger(c.slog)}, opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	child.middlewares = c.middlewares.clone()
+	child.limiter.parent = c.limiter
+	child.jobs.parent = c.jobs
+	child.readiness = c.readiness
+
+	err = ErrShuttingDown
+	c.addCount(v, nil, func(finished func()) {
+		err = nil
+		child.start()
+		go func() {
+			defer finished()
+			c.watchChild(child)
+		}()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+// watchChild shuts down (drains or finishes) the child when the parent does,
+// returns once the child is finished
+func (c *Controller) watchChild(child *Controller) {
+	done := c.ShuttingDownChan()
+	draining := c.DrainingChan()
+	for {
+		select {
+		case <-draining:
+			log.Debug("Draining child")
+			child.Drain()
+			draining = nil
+		case <-done:
+			log.Debug("Shutting down child")
+			child.Shutdown()
+			child.releaseWait() // so the child finishes even if `Wait` isnt called on it
+			done = nil
+		case <-c.finishChan:
+			child.Finish()
+			<-child.finishChan
+			return
+		case <-child.finishChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestChild(t *testing.T) {
+	t.Run("Child can be shutdown without the parent", func(t *testing.T) {
+		c, _ := NewController()
+		child, err := c.Child()
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		child.Go(foreverRunnner{})
+		child.Shutdown()
+		if err := child.Wait(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if c.IsShuttingDown() {
+			t.Errorf("expected parent to not be shutting down")
+		}
+
+		ran := false
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			ran = true
+			return nil
+		}))
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if !ran {
+			t.Errorf("expected parent to still run jobs")
+		}
+	})
+	t.Run("Parent waits for the child", func(t *testing.T) {
+		c, _ := NewController()
+		child, _ := c.Child(WithLimit(1))
+		if child.Limit() != 1 {
+			t.Errorf("expected child limit to be 1, got %v", child.Limit())
+		}
+		finished := false
+		child.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			time.Sleep(10 * time.Millisecond)
+			finished = true
+			return fmt.Errorf("bad batch")
+		}))
+
+		parentDone := make(chan error)
+		go func() {
+			parentDone <- c.Wait()
+		}()
+		select {
+		case <-parentDone:
+			t.Errorf("expected parent to wait for child")
+		case <-time.After(5 * time.Millisecond):
+		}
+
+		if err := child.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if err := <-parentDone; err != nil {
+			t.Errorf("expected child errors to not be in parent, got %v", c.Errors())
+		}
+		if !finished {
+			t.Errorf("expected child job to finish")
+		}
+	})
+	t.Run("Parent shutdown shuts down the child", func(t *testing.T) {
+		c, _ := NewController()
+		child, _ := c.Child()
+		grandchild, _ := child.Child()
+		grandchild.Go(foreverRunnner{})
+		c.Shutdown()
+		if err := grandchild.Wait(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		child.Wait()
+		c.Wait()
+
+		if _, err := c.Child(); err != ErrShuttingDown {
+			t.Errorf("expected ErrShuttingDown, got %v", err)
+		}
+	})
+	t.Run("Child jobs count toward the parent", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(1)
+		child, _ := c.Child(WithLimit(2))
+		started := make(chan struct{})
+		release := make(chan struct{})
+		child.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			close(started)
+			<-release
+			return nil
+		}), Name("child-job"))
+		<-started
+
+		if stats := c.Stats(); stats.InUse != 1 || stats.Entries["LimitedGo"].Started != 1 {
+			t.Errorf("expected the child job in the parents stats, got %+v", stats)
+		}
+		if jobs := c.Jobs(); len(jobs) != 1 || jobs[0].Name != "child-job" || jobs[0].State != JobRunning {
+			t.Errorf("expected the child job in the parents jobs, got %+v", jobs)
+		}
+
+		ran := make(chan struct{})
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			close(ran)
+			return nil
+		}))
+		select {
+		case <-ran:
+			t.Errorf("expected parent job to wait for the childs slot")
+		case <-time.After(5 * time.Millisecond):
+		}
+		close(release)
+		<-ran
+
+		child.Wait()
+		c.Wait()
+		if stats := c.Stats(); stats.InUse != 0 || stats.Entries["LimitedGo"].Succeeded != 2 {
+			t.Errorf("expected both jobs to succeed, got %+v", stats)
+		}
+		if stats := child.Stats(); stats.Entries["LimitedGo"].Succeeded != 1 {
+			t.Errorf("expected only the child job in the childs stats, got %+v", stats)
+		}
+	})
+	t.Run("Idle child workers dont hold the parents slots", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(1)
+		child, _ := c.Child(WithWorkerPool(nil))
+		time.Sleep(5 * time.Millisecond) // so the workers are waiting for jobs
+		ran := make(chan struct{})
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			close(ran)
+			return nil
+		}))
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Errorf("expected parent job to run while the child is idle")
+		}
+		child.Shutdown()
+		child.Wait()
+		c.Wait()
+	})
+	t.Run("Parent shutdown finishes a child that isnt waited on", func(t *testing.T) {
+		c, _ := NewController()
+		child, _ := c.Child()
+		child.Go(foreverRunnner{})
+		c.Go(foreverRunnner{})
+		c.Shutdown()
+
+		waited := make(chan error)
+		go func() {
+			waited <- c.Wait()
+		}()
+		select {
+		case <-waited:
+		case <-time.After(time.Second):
+			t.Errorf("expected parent to finish without waiting on the child")
+		}
+	})
+}
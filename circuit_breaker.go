@@ -0,0 +1,209 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = fmt.Errorf("circuit open")
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets every job run
+	BreakerClosed BreakerState = iota
+	// BreakerOpen short circuits every job with ErrCircuitOpen
+	BreakerOpen
+	// BreakerHalfOpen lets a few jobs run to check if the dependency is back
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerConfig configures a CircuitBreaker, zero values use the defaults
+type BreakerConfig struct {
+	FailureThreshold int           // consecutive failures before opening, defaults to 5
+	Cooldown         time.Duration // time to stay open before half open, defaults to 30s
+	HalfOpenMax      int           // jobs allowed to run at once while half open, defaults to 1
+	SuccessThreshold int           // consecutive successes while half open to close, defaults to 1
+}
+
+// CircuitBreaker stops running jobs for a dependency that keeps failing.
+// Get one with `Controller.CircuitBreaker` and use `Wrap` on the Runners
+type CircuitBreaker struct {
+	name   string
+	config BreakerConfig
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	successes int
+	trials    int // jobs running while half open
+	openedAt  time.Time
+	hooks     []func(name string, from, to BreakerState)
+}
+
+// CircuitBreaker returns the breaker named name, creating it with config if it
+// doesnt exist yet (config is ignored if it does)
+func (c *Controller) CircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+	if cb, ok := c.breakers[name]; ok {
+		return cb
+	}
+	cb := newCircuitBreaker(name, config)
+	c.breakers[name] = cb
+	return cb
+}
+
+func newCircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 5
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	if config.HalfOpenMax < 1 {
+		config.HalfOpenMax = 1
+	}
+	if config.SuccessThreshold < 1 {
+		config.SuccessThreshold = 1
+	}
+	return &CircuitBreaker{name: name, config: config}
+}
+
+// Name returns the name the breaker was created with
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.checkCooldown()
+	return cb.state
+}
+
+// OnStateChange adds a function that is called every time the state changes.
+// It is called while the breaker is locked so it should not use the breaker
+func (cb *CircuitBreaker) OnStateChange(hook func(name string, from, to BreakerState)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.hooks = append(cb.hooks, hook)
+}
+
+// Wrap returns a Runner that only runs r if the breaker allows it, otherwise
+// it returns ErrCircuitOpen. The result of r is recorded by the breaker
+func (cb *CircuitBreaker) Wrap(r Runner) Runner {
+	return &breakerRunner{cb: cb, runner: r}
+}
+
+type breakerRunner struct {
+	cb     *CircuitBreaker
+	runner Runner
+}
+
+func (br *breakerRunner) Run(rc *Controller) (err error) {
+	if !br.cb.allow() {
+		return ErrCircuitOpen
+	}
+	defer func() {
+		// a panic counts as a failure, otherwise a half open trial is never given back
+		if r := recover(); r != nil {
+			br.cb.record(fmt.Errorf("%w: %v", ErrPanic, r))
+			panic(r)
+		}
+		br.cb.record(err)
+	}()
+	return br.runner.Run(rc)
+}
+
+// allow returns if a job can run, if so `record` has to be called after
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.checkCooldown()
+	switch cb.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if cb.trials >= cb.config.HalfOpenMax {
+			return false
+		}
+		cb.trials++
+	}
+	return true
+}
+
+func (cb *CircuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	// not the dependencies fault
+	failed := err != nil && err != ErrShuttingDown
+
+	switch cb.state {
+	case BreakerClosed:
+		if !failed {
+			cb.failures = 0
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.config.FailureThreshold {
+			cb.setState(BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		if cb.trials > 0 {
+			cb.trials--
+		}
+		if failed {
+			cb.setState(BreakerOpen)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.config.SuccessThreshold {
+			cb.setState(BreakerClosed)
+		}
+	case BreakerOpen:
+		// job started before the breaker opened, nothing to do
+	}
+}
+
+// checkCooldown moves from open to half open once the cooldown is over,
+// should be called with the lock held
+func (cb *CircuitBreaker) checkCooldown() {
+	if cb.state == BreakerOpen && time.Since(cb.openedAt) >= cb.config.Cooldown {
+		cb.setState(BreakerHalfOpen)
+	}
+}
+
+// setState should be called with the lock held
+func (cb *CircuitBreaker) setState(state BreakerState) {
+	from := cb.state
+	cb.state = state
+	cb.failures = 0
+	cb.successes = 0
+	if state == BreakerOpen {
+		cb.openedAt = time.Now()
+	}
+	if state != BreakerHalfOpen {
+		cb.trials = 0
+	}
+	log.Debugf("Circuit breaker %v %v -> %v", cb.name, from, state)
+	for _, hook := range cb.hooks {
+		hook(cb.name, from, state)
+	}
+}
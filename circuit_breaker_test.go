@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("Shares breakers by name", func(t *testing.T) {
+		c, _ := NewController()
+		cb := c.CircuitBreaker("db", BreakerConfig{})
+		if c.CircuitBreaker("db", BreakerConfig{FailureThreshold: 1}) != cb {
+			t.Errorf("expected same breaker for same name")
+		}
+		if c.CircuitBreaker("api", BreakerConfig{}) == cb {
+			t.Errorf("expected different breaker for different name")
+		}
+		c.Wait()
+	})
+	t.Run("Opens, half opens and closes", func(t *testing.T) {
+		c, _ := NewController()
+		cb := c.CircuitBreaker("db", BreakerConfig{FailureThreshold: 2, Cooldown: 10 * time.Millisecond})
+		changes := []string{}
+		cb.OnStateChange(func(name string, from, to BreakerState) {
+			changes = append(changes, fmt.Sprintf("%v:%v->%v", name, from, to))
+		})
+
+		ran := 0
+		failing := cb.Wrap(RunnerFunc(func(rc *Controller) error {
+			ran++
+			return fmt.Errorf("no connection")
+		}))
+		working := cb.Wrap(RunnerFunc(func(rc *Controller) error {
+			ran++
+			return nil
+		}))
+
+		c.BGo(failing)
+		c.BGo(failing)
+		if cb.State() != BreakerOpen {
+			t.Errorf("expected breaker to be open, got %v", cb.State())
+		}
+		c.BGo(working)
+		if ran != 2 {
+			t.Errorf("expected job to be short circuited, ran %v", ran)
+		}
+
+		time.Sleep(15 * time.Millisecond)
+		if cb.State() != BreakerHalfOpen {
+			t.Errorf("expected breaker to be half-open, got %v", cb.State())
+		}
+		c.BGo(working)
+		if cb.State() != BreakerClosed {
+			t.Errorf("expected breaker to be closed, got %v", cb.State())
+		}
+
+		c.Wait()
+		if errStr := c.Errors(); errStr != "no connection, no connection, circuit open" {
+			t.Errorf("expected errors to be 'no connection, no connection, circuit open', got %v", errStr)
+		}
+		expected := []string{"db:closed->open", "db:open->half-open", "db:half-open->closed"}
+		if !slices.Equal(changes, expected) {
+			t.Errorf("expected changes to be %v, got %v", expected, changes)
+		}
+	})
+	t.Run("Failure while half open opens again", func(t *testing.T) {
+		c, _ := NewController()
+		cb := c.CircuitBreaker("db", BreakerConfig{FailureThreshold: 1, Cooldown: time.Millisecond})
+		failing := cb.Wrap(RunnerFunc(func(rc *Controller) error {
+			return fmt.Errorf("no connection")
+		}))
+		c.BGo(failing)
+		time.Sleep(2 * time.Millisecond)
+		c.BGo(failing)
+		if cb.State() != BreakerOpen {
+			t.Errorf("expected breaker to be open, got %v", cb.State())
+		}
+		c.Wait()
+	})
+	t.Run("Panics count as failures", func(t *testing.T) {
+		c, _ := NewController()
+		c.Use(Recover())
+		cb := c.CircuitBreaker("db", BreakerConfig{FailureThreshold: 1, Cooldown: time.Millisecond})
+		c.BGo(cb.Wrap(newRunner(fmt.Errorf("no connection"))))
+		time.Sleep(2 * time.Millisecond)
+		c.BGo(cb.Wrap(RunnerFunc(func(rc *Controller) error {
+			panic("oops")
+		})))
+		if cb.State() != BreakerOpen {
+			t.Errorf("expected a panic while half open to open the breaker, got %v", cb.State())
+		}
+		time.Sleep(2 * time.Millisecond)
+		c.BGo(cb.Wrap(newRunner(nil)))
+		if cb.State() != BreakerClosed {
+			t.Errorf("expected the breaker to close after the cooldown, got %v", cb.State())
+		}
+		c.Wait()
+	})
+}
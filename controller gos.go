@@ -1,55 +1,96 @@
 package runner
 
-func (c *Controller) addCount(v chan bool, function func(callback func())) {
+// addCount adds to the count v while function is running (until the callback is
+// called). j is the job being added, it can be nil if it isnt one (i.e. a worker)
+func (c *Controller) addCount(v chan bool, j *job, function func(callback func())) {
+	if j != nil {
+		c.jobs.add(j)
+	}
+	if j != nil && c.IsDraining() {
+		log.Debug("Not adding job b/c draining")
+		c.skipJob(j)
+		c.endJob(j)
+		return
+	}
 	select {
 	case <-c.doneChan:
 		log.Debug("Not adding count b/c shuting down")
+		if j != nil {
+			c.skipJob(j)
+			c.endJob(j)
+		}
 	case v <- true:
 		function(func() {
+			if j != nil {
+				c.endJob(j)
+			}
 			v <- false
 		})
 	}
 }
 
-// waitForLimiter waits until c.limit chan has room. If shutdown before, it will
+// waitForLimiter waits until c.limiter has room. If shutdown before, it will
 // skip running the function and return true (false means it ran the function)
-func (c *Controller) waitForLimiter(function func()) bool {
-	select {
-	case <-c.doneChan:
-		log.Debug("Not running limited job because shuting down")
+func (c *Controller) waitForLimiter(j *job, function func()) bool {
+	if !c.limiter.acquire(c.doneChan) {
+		log.Debugf("Not running limited %v because shuting down", j)
+		c.skipJob(j)
 		return true
-	case c.limiter <- true:
-		log.Debug("Got limiter")
-		function()
-		return false
 	}
+	log.Debug("Got limiter")
+	function()
+	return false
 }
-func (c *Controller) releaseLimiter() {
-	select {
-	case <-c.limiter:
-	default:
+
+// releaseLimiter gives back the slot used by j, j can be nil if it never ran
+func (c *Controller) releaseLimiter(j *job) {
+	if !c.limiter.release() {
 		log.Errorf("no more limiter available")
 	}
+	if j != nil && c.adaptive != nil {
+		c.adaptive.observe(c.limiter, j)
+	}
+}
+
+// runLimited runs j in the current thread once the limiter has room
+func (c *Controller) runLimited(j *job) {
+	if !c.waitForStart(j) {
+		return
+	}
+	c.waitForLimiter(j, func() {
+		c.runStarted(j, nil)
+		c.releaseLimiter(j)
+	})
 }
 
 // Go run all of these until none left
 // if the runner returns error it will add to chan a
 // and can be retrieved with `Errors()` It will continue
 // to run unlses CloseOnGoError is set to true
-func (c *Controller) Go(runner Runner) {
-	c.addCount(c.mainCountChan, func(finished func()) {
-		go func() {
+func (c *Controller) Go(runner Runner, opts ...JobOption) {
+	c.goJob(c.newJob("Go", runner, opts))
+}
+
+// goJob runs j in a new go routine, returns false if it was skipped because
+// shutting down (or draining)
+func (c *Controller) goJob(j *job) bool {
+	accepted := false
+	c.addCount(c.mainCountChan, j, func(finished func()) {
+		accepted = true
+		go c.withLabels(j, func() {
 			defer finished()
-			c.addError(runner.Run(c))
-		}()
+			c.runJob(j, nil)
+		})
 	})
+	return accepted
 }
 
 // BGo Same as `Go` but run in the current thread
-func (c *Controller) BGo(runner Runner) {
-	c.addCount(c.mainCountChan, func(finished func()) {
+func (c *Controller) BGo(runner Runner, opts ...JobOption) {
+	j := c.newJob("BGo", runner, opts)
+	c.addCount(c.mainCountChan, j, func(finished func()) {
 		defer finished()
-		c.addError(runner.Run(c))
+		c.runJob(j, nil)
 	})
 }
 
@@ -57,29 +98,42 @@ func (c *Controller) BGo(runner Runner) {
 // if the runner returns error it will add to chan a
 // and can be retrieved with `Errors()`. It will continue
 // to run unlses CloseOnGoError is set to true
-func (c *Controller) LimitedGo(runner Runner) {
-	c.addCount(c.limitCountChan, func(finished func()) {
-		go func() {
+func (c *Controller) LimitedGo(runner Runner, opts ...JobOption) {
+	j := c.newJob("LimitedGo", runner, opts)
+	c.addCount(c.limitCountChan, j, func(finished func()) {
+		if c.pool != nil {
+			c.queueJob(j, finished, nil)
+			return
+		}
+		go c.withLabels(j, func() {
 			defer finished()
-			c.waitForLimiter(func() {
-				c.addError(runner.Run(c))
-				c.releaseLimiter()
-			})
-		}()
+			c.runLimited(j)
+		})
 	})
 }
 
 // BlLimitedGo is the same as LimitedGo but it will block adding
 // to the limiter until one is free
-func (c *Controller) BlLimitedGo(runner Runner) {
+func (c *Controller) BlLimitedGo(runner Runner, opts ...JobOption) {
+	j := c.newJob("BlLimitedGo", runner, opts)
 	// need to add count first so main knows to wait for this to finish
-	c.addCount(c.limitCountChan, func(finished func()) {
-		skipped := c.waitForLimiter(func() { // block this thread until free
-			go func() {
+	c.addCount(c.limitCountChan, j, func(finished func()) {
+		if c.pool != nil {
+			started := make(chan struct{})
+			if c.queueJob(j, finished, started) {
+				select { // block this thread until a worker picks it up
+				case <-started:
+				case <-c.doneChan:
+				}
+			}
+			return
+		}
+		skipped := !c.waitForStart(j) || c.waitForLimiter(j, func() { // block this thread until free
+			go c.withLabels(j, func() {
 				defer finished()
-				c.addError(runner.Run(c))
-				c.releaseLimiter()
-			}()
+				c.runStarted(j, nil)
+				c.releaseLimiter(j)
+			})
 		})
 		if skipped {
 			finished()
@@ -89,16 +143,28 @@ func (c *Controller) BlLimitedGo(runner Runner) {
 
 // Background start new go routine that will get stopped when all `Go` created ones finish
 // if the bgRunner returns error it will gracefully shutdown everything else
-func (c *Controller) Background(bgRunner Runner) {
-	c.addCount(c.backCountChan, func(finished func()) {
-		go func() {
+func (c *Controller) Background(bgRunner Runner, opts ...JobOption) {
+	j := c.newJob("Background", bgRunner, opts)
+	c.addCount(c.backCountChan, j, func(finished func()) {
+		c.registerBackground(j)
+		go c.withLabels(j, func() {
 			defer finished()
-			err := bgRunner.Run(c)
-			if err != nil {
-				c.errorChan <- err
+			if j.exited != nil {
+				defer close(j.exited)
+			}
+			defer c.notReady(j)
+			if !c.waitForStart(j) {
+				return
+			}
+			c.jobs.start(j)
+			j.err = j.runner.Run(c.scoped(j, nil))
+			c.jobs.end(j)
+			if j.err != nil {
+				c.debugJob(j, "Failed", "error", j.err)
+				c.errorChan <- j.wrapErr()
 				c.Shutdown()
 			}
-		}()
+		})
 	})
 }
 
@@ -106,10 +172,7 @@ func (c *Controller) addError(err error) {
 	if err == nil || err == ErrShuttingDown {
 		return
 	}
-	select {
-	case c.errorChan <- err:
-		return
-	default:
-		return
-	}
+	// block instead of dropping the error if runErr is busy with another one,
+	// the job still has its count so errorChan cant be closed yet
+	c.errorChan <- err
 }
@@ -2,10 +2,14 @@ package runner
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 var ErrErrors = fmt.Errorf("error running the jobs")
@@ -28,50 +32,160 @@ type Runner interface {
 // Controller can run two types of jobs:
 // - Runner: When these finish `Done()` will be called
 // - BackgroundRunner: These should listen to `IsDone()` and gracefully exit
+//
+// The controller passed to a Runner is scoped to that job, it shares everything
+// with the controller returned by `NewController` but can answer questions
+// about the job running (i.e. `Worker`)
 type Controller struct {
+	*controller
+	job    *job    // the job this was passed to, nil if not passed to a job
+	worker *worker // the pool worker running the job, nil if not ran by a worker
+}
+
+// controller is the state shared by every Controller handle
+type controller struct {
 	//----running------
-	mainCountChan  chan bool   // true up false down
-	backCountChan  chan bool   // true up false down
-	limitCountChan chan bool   // true up false down
-	limiter        chan (bool) // used to limit the number of concurrent jobs
+	mainCountChan  chan bool // true up false down
+	backCountChan  chan bool // true up false down
+	limitCountChan chan bool // true up false down
+	limiter        *limiter  // used to limit the number of concurrent jobs
 	//----listeners------
 	doneChan   chan struct{} // used for `Done` (notify other of gracefully close)
 	finishChan chan struct{} // used for `Wait` (notify main of finished)
+	finishOnce sync.Once     // so finishChan is only closed once
+	shutHooks  chan struct{} // closed once the shutdown hooks were called (or wont be)
 	//-----Pass errors-----
-	errorChan chan error
-	errors    []string
+	errorChan    chan error
+	errorsMu     sync.Mutex // errors can still be added after a `Finish`
+	errors       []string
+	skippedCount atomic.Int64 // jobs that never ran because shutting down
 	//-----Order Restorer------
 	or *OrderRestorer
+	//-----Worker Pool------
+	pool       *workQueue // nil unless `WithWorkerPool` is used
+	workerInit WorkerInit
+	//-----Rate Limit------
+	rateLimit       *tokenBucket // nil unless `WithRateLimit` is used
+	keyedRateLimits map[string]*tokenBucket
+	//-----Adaptive Limit------
+	adaptive *adaptiveLimit // nil unless `WithAdaptiveLimit` is used
+	//-----Circuit Breakers------
+	breakersMu sync.Mutex
+	breakers   map[string]*CircuitBreaker
+	//-----Keyed------
+	keysMu sync.Mutex
+	keys   map[string]chan struct{} // closed when the last job for the key finishes
+	//-----Singleflight------
+	flights *flights
+	//-----Readiness------
+	readiness    *readinessRegistry
+	readyTimeout time.Duration
+	//-----Logging------
+	name string       // see `WithName`
+	slog *slog.Logger // see `WithLogger`
+	//-----Jobs------
+	jobs        *jobRegistry
+	hooks       *hooks
+	middlewares *middlewares
+	//-----Drain------
+	drainChan chan struct{} // closed when draining, new jobs are skipped
+	drainOnce sync.Once     // so drainChan is only closed once
+	waitOnce  sync.Once     // for releaseWait
+	//-----Pause------
+	pause *pauseState
+	//-----Ordered shutdown------
+	backgrounds *backgroundRegistry // nil unless `WithOrderedShutdown`
 }
 
+// Option is used to configure a controller when it is created
+type Option func(c *Controller) error
+
 // NewController returns a new controller with default values
-func NewController() (*Controller, error) {
-	return NewControllerWithLimit(defaultLimit)
+func NewController(opts ...Option) (*Controller, error) {
+	return NewControllerWithLimit(defaultLimit, opts...)
 }
 
 // NewControllerWithLimit returns a new controller with with a variable limit size
-func NewControllerWithLimit(limit int) (*Controller, error) {
+func NewControllerWithLimit(limit int, opts ...Option) (*Controller, error) {
+	c, err := newController(limit, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	go c.listenForCtrlC()
+	c.start()
+
+	return c, nil
+}
+
+// WithLimit changes the limit, mostly useful for `Child`
+func WithLimit(limit int) Option {
+	return func(c *Controller) error {
+		if limit < 1 {
+			return ErrInvalidLimit
+		}
+		c.limiter.setLimit(limit)
+		return nil
+	}
+}
+
+// newController returns a new controller that hasnt been started yet
+func newController(limit int, opts []Option) (*Controller, error) {
 	if limit < 1 {
 		return nil, ErrInvalidLimit
 	}
 	dc := make(chan struct{})
-	c := &Controller{
+	h := newHooks()
+	c := &Controller{controller: &controller{
 		mainCountChan:  make(chan bool),
 		backCountChan:  make(chan bool),
 		limitCountChan: make(chan bool),
-		limiter:        make(chan bool, limit),
+		limiter:        newLimiter(limit),
 		doneChan:       dc,
 		finishChan:     make(chan struct{}),
+		shutHooks:      make(chan struct{}),
 		errorChan:      make(chan error),
 		errors:         make([]string, 0),
 		or:             NewOrderRestorer(dc),
+		breakers:       make(map[string]*CircuitBreaker),
+		keys:           make(map[string]chan struct{}),
+		flights:        newFlights(),
+		readiness:      newReadinessRegistry(),
+		drainChan:      make(chan struct{}),
+		jobs:           newJobRegistry(h),
+		hooks:          h,
+		middlewares:    &middlewares{},
+		pause:          newPauseState(),
+	}}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
 	}
+	return c, nil
+}
 
-	go c.listenForCtrlC()
+// start starts the go routines that keep track of the jobs
+func (c *Controller) start() {
 	go c.runMain()
 	go c.runErr()
+	go c.runShutdownHooks()
+	if c.backgrounds != nil {
+		go c.stopBackgrounds()
+	}
 
-	return c, nil
+	if c.pool != nil {
+		workers := c.Limit()
+		if c.adaptive != nil {
+			workers = c.adaptive.config.Max // so there are enough workers if the limit grows
+		}
+		c.startWorkers(workers)
+	}
+}
+
+// scoped returns a new handle to the same controller for j ran by w (both can be nil)
+func (c *Controller) scoped(j *job, w *worker) *Controller {
+	return &Controller{controller: c.controller, job: j, worker: w}
 }
 
 //----------------Handle close-----------------
@@ -102,7 +216,11 @@ func (c *Controller) Shutdown() {
 }
 
 // ShuttingDownChan will return a channel that will be closed when the controller is shutting down
+// (or when a background job is told to stop if using `WithOrderedShutdown`)
 func (c *Controller) ShuttingDownChan() <-chan struct{} {
+	if c.job != nil && c.job.stop != nil {
+		return c.job.stop
+	}
 	return c.doneChan
 }
 
@@ -125,22 +243,46 @@ func (c *Controller) ShuttingDownErr() error {
 
 }
 
-// Finish used to exit (should call Shutdown to gracefully close everything)
+// Finish used to exit (should call Shutdown to gracefully close everything).
+// `Wait` returns right away, the finish hooks are called in the background
+// without waiting for the shutdown or error hooks
 func (c *Controller) Finish() {
 	log.Debug("Finish")
-	select {
-	case <-c.finishChan:
-		log.Debug("Already finished...")
-	default:
+	c.finish(false)
+}
+
+// finish closes finishChan once. If waitHooks (once everything is done) the
+// shutdown, error and finish hooks are called first, otherwise it is a kill
+// so it doesnt wait on them
+func (c *Controller) finish(waitHooks bool) {
+	finished := false
+	c.finishOnce.Do(func() {
+		finished = true
+		if !waitHooks {
+			close(c.finishChan)
+			go c.hooks.finishing()
+			return
+		}
+		select {
+		case <-c.doneChan:
+			<-c.shutHooks // so shutdown hooks are called before finish ones
+		default:
+		}
+		c.hooks.waitErrored()
+		c.hooks.finishing()
 		close(c.finishChan)
+	})
+	if !finished {
+		log.Debug("Already finished...")
 	}
 }
 
 // Wait will wait until all jobs are finished
 func (c *Controller) Wait() error {
-	c.mainCountChan <- false  // so that we dont close until something is waiting
-	c.limitCountChan <- false // so that we dont close until something is waiting
+	c.releaseWait()
 	<-c.finishChan
+	c.errorsMu.Lock()
+	defer c.errorsMu.Unlock()
 	if len(c.errors) == 0 {
 		return nil
 	}
@@ -148,7 +290,12 @@ func (c *Controller) Wait() error {
 	return ErrErrors
 }
 
+// Errors returns the errors added to the controller separated by commas.
+// Errors from named jobs are prefixed with the name, unnamed ones are the
+// errors they returned (see `JobError` for the id)
 func (c *Controller) Errors() string {
+	c.errorsMu.Lock()
+	defer c.errorsMu.Unlock()
 	if len(c.errors) == 0 {
 		return ""
 	}
@@ -156,6 +303,18 @@ func (c *Controller) Errors() string {
 	return strings.Join(c.errors, ", ")
 }
 
+// Limit returns the number of limited jobs that can currently run at a time
+func (c *Controller) Limit() int {
+	limit, _, _ := c.limiter.usage()
+	return limit
+}
+
+// Skipped returns the number of jobs that were submitted but never ran
+// because the controller was shutting down (or draining)
+func (c *Controller) Skipped() int {
+	return int(c.skippedCount.Load())
+}
+
 // NextOR returns an OrderRestorer and queues up the next order restorer
 func (c *Controller) NextOR() *OrderRestorer {
 	toReturn := c.or
@@ -166,8 +325,8 @@ func (c *Controller) NextOR() *OrderRestorer {
 //----------------Handle close-----------------
 
 func (c *Controller) runMain() {
-	mainCount := 1  // start with 1 so dont try closing until wait is called
-	limitCount := 1 // start with 1 so dont try closing until wait is called
+	mainCount := 1  // start with 1 so dont try closing until wait (or drain) is called
+	limitCount := 1 // start with 1 so dont try closing until wait (or drain) is called
 	backgroundCount := 0
 
 	for {
@@ -196,12 +355,13 @@ func (c *Controller) runMain() {
 			c.Shutdown()
 		}
 
-		log.Debugf("Main %v, Limmited %v, Background %v", mainCount, limitCount, backgroundCount)
+		c.debugJob(nil, "Counts", "main", mainCount, "limited", limitCount, "background", backgroundCount)
 		if mainCount+limitCount+backgroundCount == 0 {
 			select {
 			case <-c.doneChan:
 				log.Debug("Closing Error Chan")
 				close(c.errorChan)
+				return // nothing left, so anything added after should be skipped
 			default:
 				log.Debug("Not done?")
 			}
@@ -209,13 +369,28 @@ func (c *Controller) runMain() {
 	}
 }
 
+// runShutdownHooks calls the shutdown hooks once shutting down. It is its own
+// go routine since runMain is usually what calls `Shutdown`, so a slow hook
+// would stop it from keeping track of jobs
+func (c *Controller) runShutdownHooks() {
+	defer close(c.shutHooks)
+	select {
+	case <-c.doneChan:
+		c.hooks.shuttingDown()
+	case <-c.finishChan:
+	}
+}
+
 func (c *Controller) runErr() {
 	for {
 		newError, ok := <-c.errorChan
 		if !ok {
 			break
 		}
-		c.errors = append(c.errors, newError.Error())
+		c.errorsMu.Lock()
+		c.errors = append(c.errors, errorSummary(newError))
+		c.errorsMu.Unlock()
+		c.hooks.errored(newError)
 	}
-	c.Finish()
+	c.finish(true)
 }
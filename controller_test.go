@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"slices"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -218,6 +219,18 @@ func TestController(t *testing.T) {
 			t.Errorf("expected finishChan to be open after creating")
 		}
 	})
+	t.Run("Doesnt drop errors from jobs failing at the same time", func(t *testing.T) {
+		c, _ := NewController()
+		for i := 0; i < 50; i++ {
+			c.Go(newRunner(fmt.Errorf("failed")))
+		}
+		if err := c.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if count := strings.Count(c.Errors(), "failed"); count != 50 {
+			t.Errorf("expected 50 errors, got %v", count)
+		}
+	})
 	t.Run("returns error if bad limit", func(t *testing.T) {
 		_, err := NewControllerWithLimit(0)
 		if err != ErrInvalidLimit {
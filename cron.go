@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCron = fmt.Errorf("invalid cron expression")
+
+// Schedule returns when a scheduled job should run next
+type Schedule interface {
+	// Next returns the next time after `after` (in the same location)
+	Next(after time.Time) time.Time
+}
+
+// Every returns a Schedule that runs every interval (has to be greater than
+// 0, see `Controller.Every`)
+func Every(interval time.Duration) Schedule {
+	return everySchedule(interval)
+}
+
+type everySchedule time.Duration
+
+func (e everySchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// cronSchedule is a parsed cron expression, each field is a bit set of the
+// values that match
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{0, 59, nil}, // second
+	{0, 59, nil}, // minute
+	{0, 23, nil}, // hour
+	{1, 31, nil}, // day of month
+	{1, 12, map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}},
+	{0, 7, map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}}, // day of week, 7 is also sunday
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseCron parses a standard 5 field cron expression (minute, hour, day of
+// month, month, day of week) or 6 fields with seconds first. Fields support
+// `*`, lists (`1,2`), ranges (`1-5`), steps (`*/15`) and month/day names.
+// `@yearly`, `@monthly`, `@weekly`, `@daily` and `@hourly` are also supported
+func ParseCron(expr string) (Schedule, error) {
+	if descriptor, ok := cronDescriptors[strings.TrimSpace(expr)]; ok {
+		expr = descriptor
+	}
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: expected 5 or 6 fields, got %v", ErrInvalidCron, len(fields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+	dow := bits[5]
+	if dow&(1<<7) != 0 {
+		dow |= 1 // 7 is sunday
+	}
+	return &cronSchedule{
+		second:  bits[0],
+		minute:  bits[1],
+		hour:    bits[2],
+		dom:     bits[3],
+		month:   bits[4],
+		dow:     dow,
+		domStar: fields[3] == "*" || fields[3] == "?",
+		dowStar: fields[5] == "*" || fields[5] == "?",
+	}, nil
+}
+
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("%w: bad step %q", ErrInvalidCron, part)
+			}
+		}
+
+		start, end := f.min, f.max
+		if rangePart != "*" && rangePart != "?" {
+			low, high, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = f.value(low); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = f.value(high); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = f.max // 5/10 means starting at 5
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("%w: bad range %q", ErrInvalidCron, part)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%w: %q should be between %v and %v", ErrInvalidCron, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (cs *cronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(5, 0, 0) // impossible expressions like 30 feb
+
+	for t.Before(limit) {
+		if cs.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !cs.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if cs.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if cs.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if cs.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches if either day field is `*` both have to match, otherwise either
+// can (same as standard cron)
+func (cs *cronSchedule) dayMatches(t time.Time) bool {
+	dom := cs.dom&(1<<uint(t.Day())) != 0
+	dow := cs.dow&(1<<uint(t.Weekday())) != 0
+	if cs.domStar || cs.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
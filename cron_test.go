@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCron(t *testing.T) {
+	utc := func(s string) time.Time {
+		v, err := time.Parse(time.DateTime, s)
+		if err != nil {
+			t.Fatalf("bad time %v", s)
+		}
+		return v
+	}
+
+	tests := []struct {
+		expr  string
+		after string
+		next  string
+	}{
+		{"* * * * *", "2024-01-01 10:00:30", "2024-01-01 10:01:00"},
+		{"*/15 * * * *", "2024-01-01 10:16:00", "2024-01-01 10:30:00"},
+		{"30 * * * * *", "2024-01-01 10:00:30", "2024-01-01 10:01:30"},
+		{"0 9-17 * * mon-fri", "2024-01-05 17:00:00", "2024-01-08 09:00:00"},
+		{"0 0 1 jan,jul *", "2024-02-10 00:00:00", "2024-07-01 00:00:00"},
+		{"0 0 13 * 5", "2024-01-01 00:00:00", "2024-01-05 00:00:00"}, // 13th or friday
+		{"0 0 * * 7", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"5/20 0 * * *", "2024-01-01 00:06:00", "2024-01-01 00:25:00"},
+		{"0 0 29 2 *", "2024-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"@hourly", "2024-01-01 10:59:59", "2024-01-01 11:00:00"},
+		{"@weekly", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+	}
+	for _, test := range tests {
+		schedule, err := ParseCron(test.expr)
+		if err != nil {
+			t.Errorf("expected no error for %v, got %v", test.expr, err)
+			continue
+		}
+		if next := schedule.Next(utc(test.after)); !next.Equal(utc(test.next)) {
+			t.Errorf("expected %v after %v to be %v, got %v", test.expr, test.after, test.next, next)
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * * foo *", "5-1 * * * *", "*/0 * * * *"} {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("expected invalid cron error for %v, got %v", expr, err)
+		}
+	}
+
+	schedule, _ := ParseCron("0 0 31 2 *")
+	if next := schedule.Next(utc("2024-01-01 00:00:00")); !next.IsZero() {
+		t.Errorf("expected impossible expression to have no next, got %v", next)
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+	schedule, _ = ParseCron("0 9 * * *")
+	next := schedule.Next(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).In(newYork))
+	if !next.Equal(time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 9am new york, got %v", next)
+	}
+}
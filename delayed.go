@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"sync"
+	"time"
+)
+
+// DelayedJob is a job scheduled to run later, see `GoAfter`
+type DelayedJob struct {
+	at     time.Time
+	cancel chan struct{}
+	mu     sync.Mutex
+	taken  bool // true once it started, was cancelled or skipped
+}
+
+// At returns when the job is scheduled to run
+func (d *DelayedJob) At() time.Time {
+	return d.at
+}
+
+// Cancel stops the job from running. Returns false if it is too late (it
+// already started or was skipped because shutting down)
+func (d *DelayedJob) Cancel() bool {
+	if !d.take() {
+		return false
+	}
+	close(d.cancel)
+	return true
+}
+
+// take returns true the first time it is called
+func (d *DelayedJob) take() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.taken {
+		return false
+	}
+	d.taken = true
+	return true
+}
+
+// GoAfter is the same as `Go` but the job runs after delay. It counts as a
+// job while waiting so `Wait` wont return before it runs. If shutting down
+// before then it is skipped
+func (c *Controller) GoAfter(delay time.Duration, runner Runner, opts ...JobOption) *DelayedJob {
+	return c.GoAt(time.Now().Add(delay), runner, opts...)
+}
+
+// GoAt is the same as `GoAfter` but runs at a time
+func (c *Controller) GoAt(at time.Time, runner Runner, opts ...JobOption) *DelayedJob {
+	j := c.newJob("GoAt", runner, opts)
+	d := &DelayedJob{at: at, cancel: make(chan struct{})}
+	accepted := false
+	c.addCount(c.mainCountChan, j, func(finished func()) {
+		accepted = true
+		go c.withLabels(j, func() {
+			defer finished()
+			if c.waitUntil(d, j) {
+				c.runJob(j, nil)
+			}
+		})
+	})
+	if !accepted {
+		d.take() // skipped because shutting down, too late to cancel
+	}
+	return d
+}
+
+// LimitedGoAfter is the same as `LimitedGo` but the job is added to the
+// limiter after delay (see `GoAfter`)
+func (c *Controller) LimitedGoAfter(delay time.Duration, runner Runner, opts ...JobOption) *DelayedJob {
+	return c.LimitedGoAt(time.Now().Add(delay), runner, opts...)
+}
+
+// LimitedGoAt is the same as `LimitedGoAfter` but runs at a time
+func (c *Controller) LimitedGoAt(at time.Time, runner Runner, opts ...JobOption) *DelayedJob {
+	j := c.newJob("LimitedGoAt", runner, opts)
+	d := &DelayedJob{at: at, cancel: make(chan struct{})}
+	accepted := false
+	c.addCount(c.limitCountChan, j, func(finished func()) {
+		accepted = true
+		go c.withLabels(j, func() {
+			if !c.waitUntil(d, j) {
+				finished()
+				return
+			}
+			if c.pool != nil {
+				c.queueJob(j, finished, nil)
+				return
+			}
+			defer finished()
+			c.runLimited(j)
+		})
+	})
+	if !accepted {
+		d.take() // skipped because shutting down, too late to cancel
+	}
+	return d
+}
+
+// waitUntil waits for the time the job should run. Returns false if it was
+// cancelled or skipped because shutting down
+func (c *Controller) waitUntil(d *DelayedJob, j *job) bool {
+	timer := time.NewTimer(time.Until(d.at))
+	defer timer.Stop()
+	select {
+	case <-d.cancel:
+		log.Debugf("Delayed %v cancelled", j)
+		j.cancelled = true
+		return false
+	case <-c.doneChan:
+		if d.take() {
+			log.Debugf("Not running delayed %v because shuting down", j)
+			c.skipJob(j)
+			return false
+		}
+		// cancelled at the same time
+	case <-timer.C:
+		if d.take() {
+			return true
+		}
+	}
+	j.cancelled = true
+	return false
+}
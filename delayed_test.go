@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDelayed(t *testing.T) {
+	t.Run("Wait waits for delayed jobs", func(t *testing.T) {
+		c, _ := NewController()
+		count := atomic.Int32{}
+		job := RunnerFunc(func(rc *Controller) error {
+			count.Add(1)
+			return nil
+		})
+		start := time.Now()
+		c.GoAfter(5*time.Millisecond, job)
+		c.LimitedGoAfter(5*time.Millisecond, job)
+		c.GoAt(start.Add(10*time.Millisecond), job)
+		d := c.LimitedGoAt(start.Add(10*time.Millisecond), job)
+		if !d.At().Equal(start.Add(10 * time.Millisecond)) {
+			t.Errorf("expected at to be 10ms from start, got %v", d.At())
+		}
+
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+			t.Errorf("expected to wait for delayed jobs, took %v", elapsed)
+		}
+		if count.Load() != 4 {
+			t.Errorf("expected 4 jobs to run, got %v", count.Load())
+		}
+		if d.Cancel() {
+			t.Errorf("expected cancel to return false after running")
+		}
+	})
+	t.Run("Cancelled jobs dont run", func(t *testing.T) {
+		c, _ := NewController(WithWorkerPool(nil))
+		ran := false
+		job := RunnerFunc(func(rc *Controller) error {
+			ran = true
+			return nil
+		})
+		d1 := c.GoAfter(time.Hour, job)
+		d2 := c.LimitedGoAfter(time.Hour, job)
+		if !d1.Cancel() || !d2.Cancel() {
+			t.Errorf("expected cancel to return true")
+		}
+		if d1.Cancel() {
+			t.Errorf("expected second cancel to return false")
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+		if ran {
+			t.Errorf("expected cancelled jobs to not run")
+		}
+		if c.Skipped() != 0 {
+			t.Errorf("expected cancelled jobs to not count as skipped, got %v", c.Skipped())
+		}
+		stats := c.Stats()
+		if e := stats.Entries["GoAt"]; e.Cancelled != 1 || e.Skipped != 0 || e.Active() != 0 {
+			t.Errorf("expected GoAt job to be cancelled, got %+v", e)
+		}
+		if e := stats.Entries["LimitedGoAt"]; e.Cancelled != 1 || e.Skipped != 0 {
+			t.Errorf("expected LimitedGoAt job to be cancelled, got %+v", e)
+		}
+		for _, job := range c.Jobs() {
+			if job.State != JobCancelled {
+				t.Errorf("expected cancelled state, got %v", job.State)
+			}
+		}
+	})
+	t.Run("Skipped if shutting down first", func(t *testing.T) {
+		c, _ := NewController()
+		ran := false
+		d := c.GoAfter(time.Hour, RunnerFunc(func(rc *Controller) error {
+			ran = true
+			return nil
+		}))
+		c.Shutdown()
+		c.Wait()
+		if ran {
+			t.Errorf("expected delayed job to be skipped")
+		}
+		if c.Skipped() != 1 {
+			t.Errorf("expected 1 skipped, got %v", c.Skipped())
+		}
+		if d.Cancel() {
+			t.Errorf("expected cancel to return false after skipped")
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package runner
+
+// Drain stops accepting new jobs (they are skipped) but unlike `Shutdown`
+// everything already submitted, including limited jobs still waiting for the
+// limiter, keeps running. Once they are all done the controller shuts down as
+// if `Wait` was called
+func (c *Controller) Drain() {
+	draining := false
+	c.drainOnce.Do(func() {
+		draining = true
+		log.Info("Draining...")
+		close(c.drainChan)
+	})
+	if !draining {
+		log.Debug("Already draining...")
+		return
+	}
+	c.releaseWait()
+}
+
+// DrainingChan will return a channel that will be closed when the controller is draining
+func (c *Controller) DrainingChan() <-chan struct{} {
+	return c.drainChan
+}
+
+// IsDraining will return true if the controller is draining
+func (c *Controller) IsDraining() bool {
+	select {
+	case <-c.drainChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// releaseWait removes the counts runMain starts with so it can shutdown once
+// nothing is left, only the first call does anything
+func (c *Controller) releaseWait() {
+	c.waitOnce.Do(func() {
+		c.mainCountChan <- false
+		c.limitCountChan <- false
+	})
+}
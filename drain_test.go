@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	for name, opts := range map[string][]Option{"": nil, " with worker pool": {WithWorkerPool(nil)}} {
+		t.Run("Queued limited jobs still run"+name, func(t *testing.T) {
+			c, _ := NewControllerWithLimit(1, opts...)
+			count := atomic.Int32{}
+			job := RunnerFunc(func(rc *Controller) error {
+				time.Sleep(time.Millisecond)
+				count.Add(1)
+				return nil
+			})
+			for range 5 {
+				c.LimitedGo(job)
+			}
+			c.Drain()
+			if !c.IsDraining() {
+				t.Errorf("expected to be draining")
+			}
+			c.LimitedGo(job)
+			c.Go(job)
+
+			if err := c.Wait(); err != nil {
+				t.Errorf("expected no errors, got %v", err)
+			}
+			if count.Load() != 5 {
+				t.Errorf("expected 5 jobs to run, got %v", count.Load())
+			}
+			if c.Skipped() != 2 {
+				t.Errorf("expected 2 skipped, got %v", c.Skipped())
+			}
+		})
+	}
+	t.Run("Shuts down once drained without Wait", func(t *testing.T) {
+		c, _ := NewController()
+		stopped := make(chan struct{})
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			<-rc.ShuttingDownChan()
+			close(stopped)
+			return nil
+		}))
+		c.LimitedGo(newRunner(nil))
+		c.Drain()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Errorf("expected to shutdown after draining")
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+	})
+	t.Run("Drains children", func(t *testing.T) {
+		c, _ := NewController()
+		child, _ := c.Child()
+		ran := atomic.Bool{}
+		child.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			<-rc.DrainingChan()
+			ran.Store(true)
+			return nil
+		}))
+		c.Drain()
+		c.Wait()
+		if !ran.Load() {
+			t.Errorf("expected child job to finish")
+		}
+		if !child.IsDraining() {
+			t.Errorf("expected child to be draining")
+		}
+	})
+	t.Run("Drain can be called concurrently", func(t *testing.T) {
+		c, _ := NewController()
+		c.Go(newRunner(nil))
+		start := make(chan struct{})
+		wg := sync.WaitGroup{}
+		for range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				c.Drain()
+			}()
+		}
+		close(start)
+		wg.Wait()
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+	})
+}
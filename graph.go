@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrDuplicateNode = fmt.Errorf("node already added")
+var ErrUnknownDependency = fmt.Errorf("unknown dependency")
+var ErrCycle = fmt.Errorf("dependency cycle")
+var ErrDependencyFailed = fmt.Errorf("dependency failed")
+
+// NodeState is the outcome of a node in a Graph
+type NodeState int
+
+const (
+	// NodeSucceeded the runner returned no error
+	NodeSucceeded NodeState = iota
+	// NodeFailed the runner returned an error
+	NodeFailed
+	// NodeSkipped the runner was not ran because a dependency failed or shutting down
+	NodeSkipped
+)
+
+func (s NodeState) String() string {
+	switch s {
+	case NodeSucceeded:
+		return "succeeded"
+	case NodeFailed:
+		return "failed"
+	case NodeSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("NodeState(%d)", int(s))
+	}
+}
+
+// NodeResult is the result of running a node in a Graph
+type NodeResult struct {
+	Name    string
+	State   NodeState
+	Err     error
+	Started time.Time // zero if skipped
+	Ended   time.Time // zero if skipped
+}
+
+// Graph is a set of named Runners that depend on each other. Each node is ran
+// with `LimitedGo` (and the `Name` of the node) as soon as all of its
+// dependencies succeeded
+type Graph struct {
+	nodes map[string]*graphNode
+	order []string // names in the order added
+	err   error    // first error from `Add`
+}
+
+type graphNode struct {
+	name       string
+	runner     Runner
+	deps       []string
+	dependents []string
+}
+
+// NewGraph returns an empty Graph
+func NewGraph() *Graph {
+	return &Graph{nodes: make(map[string]*graphNode)}
+}
+
+// Add adds a node named name that runs r after all of deps succeeded. deps
+// dont have to be added yet, they are checked by `Build`
+func (g *Graph) Add(name string, r Runner, deps ...string) {
+	if _, ok := g.nodes[name]; ok {
+		if g.err == nil {
+			g.err = fmt.Errorf("%w: %v", ErrDuplicateNode, name)
+		}
+		return
+	}
+	g.nodes[name] = &graphNode{name: name, runner: r, deps: deps}
+	g.order = append(g.order, name)
+}
+
+// Build checks every dependency was added and there are no cycles
+func (g *Graph) Build() error {
+	if g.err != nil {
+		return g.err
+	}
+	for _, n := range g.nodes {
+		n.dependents = nil
+	}
+	for _, name := range g.order {
+		n := g.nodes[name]
+		for _, dep := range n.deps {
+			d, ok := g.nodes[dep]
+			if !ok {
+				return fmt.Errorf("%w: %v depends on %v", ErrUnknownDependency, name, dep)
+			}
+			d.dependents = append(d.dependents, name)
+		}
+	}
+
+	// depth first, 1 is being visited, 2 is visited
+	visited := make(map[string]int, len(g.nodes))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		path = append(path, name)
+		switch visited[name] {
+		case 1:
+			return fmt.Errorf("%w: %v", ErrCycle, strings.Join(path, " -> "))
+		case 2:
+			return nil
+		}
+		visited[name] = 1
+		for _, dep := range g.nodes[name].deps {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		visited[name] = 2
+		return nil
+	}
+	for _, name := range g.order {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run builds the graph and runs it on c, blocking until every node finished
+// or was skipped. The results are in the order the nodes were added, the
+// error is ErrErrors if any node didnt succeed
+func (g *Graph) Run(c *Controller) ([]NodeResult, error) {
+	if err := g.Build(); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*NodeResult, len(g.nodes))
+	waiting := make(map[string]int, len(g.nodes)) // dependencies not finished
+	for _, name := range g.order {
+		results[name] = &NodeResult{Name: name}
+		waiting[name] = len(g.nodes[name].deps)
+	}
+
+	ended := make(chan *graphNode, len(g.nodes))
+	start := func(n *graphNode) {
+		c.LimitedGo(n.runner, Name(n.name), whenEnded(func(j *job) {
+			result := results[n.name]
+			result.Started, result.Ended, result.Err = j.started, j.ended, j.err
+			switch {
+			case j.skipped:
+				result.State = NodeSkipped
+				result.Err = ErrShuttingDown
+			case j.err != nil:
+				result.State = NodeFailed
+			default:
+				result.State = NodeSucceeded
+			}
+			ended <- n
+		}))
+	}
+
+	// skip marks name and everything depending on it as skipped, returns how many
+	var skip func(name string, dep string) int
+	skip = func(name string, dep string) int {
+		result := results[name]
+		if result.Err != nil {
+			return 0 // already skipped through another dependency
+		}
+		result.State = NodeSkipped
+		result.Err = fmt.Errorf("%w: %v", ErrDependencyFailed, dep)
+		count := 1
+		for _, dependent := range g.nodes[name].dependents {
+			count += skip(dependent, name)
+		}
+		return count
+	}
+
+	pending := len(g.order)
+	for _, name := range g.order {
+		if waiting[name] == 0 {
+			start(g.nodes[name])
+		}
+	}
+	for pending > 0 {
+		name := (<-ended).name
+		pending--
+		if results[name].State != NodeSucceeded {
+			for _, dependent := range g.nodes[name].dependents {
+				pending -= skip(dependent, name)
+			}
+			continue
+		}
+		for _, dependent := range g.nodes[name].dependents {
+			waiting[dependent]--
+			if waiting[dependent] == 0 && results[dependent].Err == nil {
+				start(g.nodes[dependent])
+			}
+		}
+	}
+
+	toReturn := make([]NodeResult, 0, len(g.order))
+	var err error
+	for _, name := range g.order {
+		if results[name].State != NodeSucceeded {
+			err = ErrErrors
+		}
+		toReturn = append(toReturn, *results[name])
+	}
+	return toReturn, err
+}
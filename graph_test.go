@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	t.Run("Build returns errors for bad graphs", func(t *testing.T) {
+		g := NewGraph()
+		g.Add("fetch", newRunner(nil))
+		g.Add("fetch", newRunner(nil))
+		if err := g.Build(); !errors.Is(err, ErrDuplicateNode) {
+			t.Errorf("expected duplicate node error, got %v", err)
+		}
+
+		g = NewGraph()
+		g.Add("compile", newRunner(nil), "fetch")
+		if err := g.Build(); !errors.Is(err, ErrUnknownDependency) {
+			t.Errorf("expected unknown dependency error, got %v", err)
+		}
+
+		g = NewGraph()
+		g.Add("a", newRunner(nil), "c")
+		g.Add("b", newRunner(nil), "a")
+		g.Add("c", newRunner(nil), "b")
+		err := g.Build()
+		if !errors.Is(err, ErrCycle) {
+			t.Errorf("expected cycle error, got %v", err)
+		}
+		if err.Error() != "dependency cycle: a -> c -> b -> a" {
+			t.Errorf("expected cycle path in error, got %v", err)
+		}
+	})
+	t.Run("Runs nodes after their dependencies", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(2)
+		mu := sync.Mutex{}
+		order := []string{}
+		node := func(name string) Runner {
+			return RunnerFunc(func(rc *Controller) error {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+				return nil
+			})
+		}
+
+		g := NewGraph()
+		g.Add("release", node("release"), "compile", "test")
+		g.Add("compile", node("compile"), "fetch", "generate")
+		g.Add("test", node("test"), "compile")
+		g.Add("fetch", node("fetch"))
+		g.Add("generate", node("generate"))
+
+		results, err := g.Run(c)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+
+		index := func(name string) int { return slices.Index(order, name) }
+		if index("compile") < index("fetch") || index("compile") < index("generate") {
+			t.Errorf("expected compile after fetch and generate, got %v", order)
+		}
+		if index("test") < index("compile") || index("release") < index("test") {
+			t.Errorf("expected compile, test, release, got %v", order)
+		}
+		for i, name := range []string{"release", "compile", "test", "fetch", "generate"} {
+			if results[i].Name != name || results[i].State != NodeSucceeded {
+				t.Errorf("expected %v to succeed, got %v %v", name, results[i].Name, results[i].State)
+			}
+		}
+	})
+	t.Run("Skips dependents of failed nodes", func(t *testing.T) {
+		c, _ := NewController()
+		g := NewGraph()
+		g.Add("fetch", RunnerFunc(func(rc *Controller) error {
+			return fmt.Errorf("no network")
+		}))
+		g.Add("generate", newRunner(nil))
+		g.Add("compile", newRunner(nil), "fetch", "generate")
+		g.Add("test", newRunner(nil), "compile")
+
+		results, err := g.Run(c)
+		if err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		c.Wait()
+
+		expected := []NodeState{NodeFailed, NodeSucceeded, NodeSkipped, NodeSkipped}
+		for i, state := range expected {
+			if results[i].State != state {
+				t.Errorf("expected %v to be %v, got %v", results[i].Name, state, results[i].State)
+			}
+		}
+		if !errors.Is(results[2].Err, ErrDependencyFailed) || results[2].Err.Error() != "dependency failed: fetch" {
+			t.Errorf("expected compile to fail because of fetch, got %v", results[2].Err)
+		}
+		if results[3].Err.Error() != "dependency failed: compile" {
+			t.Errorf("expected test to fail because of compile, got %v", results[3].Err)
+		}
+		if c.Errors() != "fetch: no network" {
+			t.Errorf("expected the error to have the node name, got %v", c.Errors())
+		}
+	})
+	t.Run("Nodes are skipped if shutting down", func(t *testing.T) {
+		c, _ := NewController()
+		c.Shutdown()
+		g := NewGraph()
+		g.Add("fetch", newRunner(nil))
+		g.Add("compile", newRunner(nil), "fetch")
+		results, err := g.Run(c)
+		if err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if results[0].State != NodeSkipped || results[0].Err != ErrShuttingDown {
+			t.Errorf("expected fetch to be skipped, got %v %v", results[0].State, results[0].Err)
+		}
+		if results[1].State != NodeSkipped {
+			t.Errorf("expected compile to be skipped, got %v", results[1].State)
+		}
+		c.Wait()
+	})
+}
@@ -0,0 +1,233 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of an `Event`
+type EventType int
+
+const (
+	EventJobStart EventType = iota
+	EventJobEnd
+	EventError
+	EventShutdown
+	EventFinish
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventJobStart:
+		return "job start"
+	case EventJobEnd:
+		return "job end"
+	case EventError:
+		return "error"
+	case EventShutdown:
+		return "shutdown"
+	case EventFinish:
+		return "finish"
+	}
+	return fmt.Sprintf("EventType(%d)", int(e))
+}
+
+// Event is sent to subscribers, see `Subscribe`
+type Event struct {
+	Type EventType
+	Time time.Time
+	Job  JobSnapshot // for EventJobStart and EventJobEnd
+	Err  error       // for EventError
+}
+
+// hooks are the functions and subscribers called on lifecycle events
+type hooks struct {
+	mu          sync.Mutex
+	jobStart    []func(job JobSnapshot)
+	jobEnd      []func(job JobSnapshot)
+	errors      []func(err error)
+	shutdown    []func()
+	finish      []func()
+	subscribers map[chan Event]struct{}
+	finished    bool       // true once the finish hooks were called
+	errQueue    []error    // waiting for the error hooks
+	callingErr  bool       // true while a go routine is calling the error hooks
+	errIdle     *sync.Cond // broadcast once errQueue is empty
+}
+
+func newHooks() *hooks {
+	h := &hooks{subscribers: make(map[chan Event]struct{})}
+	h.errIdle = sync.NewCond(&h.mu)
+	return h
+}
+
+// OnJobStart calls fn when a job starts running. Hooks are called from the
+// jobs go routines so shouldnt block
+func (c *Controller) OnJobStart(fn func(job JobSnapshot)) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	c.hooks.jobStart = append(c.hooks.jobStart, fn)
+}
+
+// OnJobEnd calls fn when a job ran or was skipped (see `JobSnapshot.State`)
+func (c *Controller) OnJobEnd(fn func(job JobSnapshot)) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	c.hooks.jobEnd = append(c.hooks.jobEnd, fn)
+}
+
+// OnError calls fn for every error added to the controller (see `Errors`), in
+// the order they were added. It is called in its own go routine so a slow fn
+// doesnt stop failing jobs from ending, `OnFinish` hooks wait for it
+func (c *Controller) OnError(fn func(err error)) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	c.hooks.errors = append(c.hooks.errors, fn)
+}
+
+// OnShutdown calls fn once the controller starts shutting down. It is called
+// in its own go routine, so it can wait for jobs to exit (`OnFinish` hooks
+// wait for it)
+func (c *Controller) OnShutdown(fn func()) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	c.hooks.shutdown = append(c.hooks.shutdown, fn)
+}
+
+// OnFinish calls fn once the controller is finished, before `Wait` returns
+// (unless `Finish` was called, then Wait doesnt wait for it)
+func (c *Controller) OnFinish(fn func()) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	c.hooks.finish = append(c.hooks.finish, fn)
+}
+
+// Subscribe returns a channel that gets every lifecycle `Event` until the
+// controller is finished (the last is EventFinish, then it is closed) or
+// unsubscribe is called. If the buffer is full events are dropped instead of
+// blocking jobs
+func (c *Controller) Subscribe(buffer int) (events <-chan Event, unsubscribe func()) {
+	ch := make(chan Event, buffer)
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+	if c.hooks.finished {
+		close(ch)
+		return ch, func() {}
+	}
+	c.hooks.subscribers[ch] = struct{}{}
+	return ch, func() {
+		c.hooks.mu.Lock()
+		defer c.hooks.mu.Unlock()
+		if _, ok := c.hooks.subscribers[ch]; ok {
+			delete(c.hooks.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// send sends e to the subscribers, should be called with the lock held
+func (h *hooks) send(e Event) {
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+			log.Debugf("Dropping %v event, subscriber is full", e.Type)
+		}
+	}
+}
+
+func (h *hooks) jobStarted(job JobSnapshot) {
+	h.mu.Lock()
+	h.send(Event{Type: EventJobStart, Time: time.Now(), Job: job})
+	fns := h.jobStart[:len(h.jobStart):len(h.jobStart)]
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn(job)
+	}
+}
+
+func (h *hooks) jobEnded(job JobSnapshot) {
+	h.mu.Lock()
+	h.send(Event{Type: EventJobEnd, Time: time.Now(), Job: job})
+	fns := h.jobEnd[:len(h.jobEnd):len(h.jobEnd)]
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn(job)
+	}
+}
+
+// errored queues err for the error hooks, they are called by one go routine
+// at a time so they are still in order
+func (h *hooks) errored(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.send(Event{Type: EventError, Time: time.Now(), Err: err})
+	h.errQueue = append(h.errQueue, err)
+	if !h.callingErr {
+		h.callingErr = true
+		go h.callErrored()
+	}
+}
+
+// callErrored calls the error hooks until errQueue is empty
+func (h *hooks) callErrored() {
+	h.mu.Lock()
+	for len(h.errQueue) > 0 {
+		err := h.errQueue[0]
+		h.errQueue = h.errQueue[1:]
+		fns := h.errors[:len(h.errors):len(h.errors)]
+		h.mu.Unlock()
+		for _, fn := range fns {
+			fn(err)
+		}
+		h.mu.Lock()
+	}
+	h.callingErr = false
+	h.errIdle.Broadcast()
+	h.mu.Unlock()
+}
+
+func (h *hooks) shuttingDown() {
+	h.mu.Lock()
+	h.send(Event{Type: EventShutdown, Time: time.Now()})
+	fns := h.shutdown[:len(h.shutdown):len(h.shutdown)]
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// waitErrored waits until the error hooks were called for every error
+func (h *hooks) waitErrored() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for h.callingErr {
+		h.errIdle.Wait()
+	}
+}
+
+// finishing calls the finish hooks and closes the subscribers, only the first
+// call does anything
+func (h *hooks) finishing() {
+	h.mu.Lock()
+	if h.finished {
+		h.mu.Unlock()
+		return
+	}
+	h.finished = true
+	fns := h.finish[:len(h.finish):len(h.finish)]
+	h.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.send(Event{Type: EventFinish, Time: time.Now()})
+	for ch := range h.subscribers {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func isError(s string) bool         { return s == "error" }
+func isErrorEvent(e EventType) bool { return e == EventError }
+
+func TestHooks(t *testing.T) {
+	t.Run("Calls the hooks", func(t *testing.T) {
+		c, _ := NewController()
+		mu := sync.Mutex{}
+		called := []string{}
+		record := func(s string) {
+			mu.Lock()
+			defer mu.Unlock()
+			called = append(called, s)
+		}
+		errFailed := fmt.Errorf("failed")
+		c.OnJobStart(func(job JobSnapshot) { record("start " + job.Name) })
+		c.OnJobEnd(func(job JobSnapshot) { record("end " + job.Name + " " + job.State.String()) })
+		c.OnError(func(err error) {
+			if !errors.Is(err, errFailed) {
+				t.Errorf("expected errFailed, got %v", err)
+			}
+			record("error")
+		})
+		c.OnShutdown(func() { record("shutdown") })
+		c.OnFinish(func() { record("finish") })
+
+		c.BGo(newRunner(errFailed), Name("job"))
+		c.Wait()
+
+		// errors are added in their own go routine so can be any time before finish
+		expected := []string{"start job", "end job failed", "shutdown", "finish"}
+		if len(called) != 5 || fmt.Sprint(slices.DeleteFunc(slices.Clone(called), isError)) != fmt.Sprint(expected) {
+			t.Errorf("expected %v and an error, got %v", expected, called)
+		}
+	})
+	t.Run("Subscribers get events until finished", func(t *testing.T) {
+		c, _ := NewController()
+		events, _ := c.Subscribe(100)
+		c.BGo(newRunner(fmt.Errorf("failed")))
+		c.Wait()
+
+		types := []EventType{}
+		for e := range events {
+			types = append(types, e.Type)
+		}
+		expected := []EventType{EventJobStart, EventJobEnd, EventShutdown, EventFinish}
+		if len(types) != 5 || fmt.Sprint(slices.DeleteFunc(slices.Clone(types), isErrorEvent)) != fmt.Sprint(expected) {
+			t.Errorf("expected %v and an error, got %v", expected, types)
+		}
+
+		late, _ := c.Subscribe(1)
+		if _, ok := <-late; ok {
+			t.Errorf("expected subscribing after finished to be closed")
+		}
+	})
+	t.Run("Unsubscribe closes the channel", func(t *testing.T) {
+		c, _ := NewController()
+		events, unsubscribe := c.Subscribe(0)
+		unsubscribe()
+		unsubscribe()
+		if _, ok := <-events; ok {
+			t.Errorf("expected closed channel")
+		}
+		c.Go(newRunner(nil)) // full (unbuffered) subscribers dont block
+		c.Wait()
+	})
+	t.Run("Finish can be called concurrently with slow hooks", func(t *testing.T) {
+		c, _ := NewController()
+		calls := atomic.Int32{}
+		c.OnFinish(func() {
+			calls.Add(1)
+			time.Sleep(20 * time.Millisecond)
+		})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Finish()
+		}()
+		c.Finish()
+		<-done
+		c.Wait()
+		time.Sleep(30 * time.Millisecond) // finish hooks are called in the background
+		if calls.Load() != 1 {
+			t.Errorf("expected finish hooks to be called once, got %v", calls.Load())
+		}
+	})
+	t.Run("Finish doesnt wait for blocked hooks", func(t *testing.T) {
+		c, _ := NewController()
+		block := make(chan struct{})
+		defer close(block)
+		c.OnShutdown(func() { <-block })
+		c.OnError(func(err error) { <-block })
+		c.BGo(newRunner(fmt.Errorf("failed")))
+		c.Shutdown()
+
+		waited := make(chan struct{})
+		go func() {
+			defer close(waited)
+			c.Finish()
+			c.Wait()
+		}()
+		select {
+		case <-waited:
+		case <-time.After(time.Second):
+			t.Errorf("expected Finish to not wait for the shutdown and error hooks")
+		}
+	})
+	t.Run("Shutdown hooks can wait for background jobs", func(t *testing.T) {
+		c, _ := NewController()
+		exited := make(chan struct{})
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			<-rc.ShuttingDownChan()
+			close(exited)
+			return nil
+		}))
+		order := []string{}
+		c.OnShutdown(func() {
+			<-exited
+			time.Sleep(time.Millisecond) // so the count for the job goes down first
+			order = append(order, "shutdown")
+		})
+		c.OnFinish(func() { order = append(order, "finish") })
+		c.Go(newRunner(nil))
+
+		waited := make(chan struct{})
+		go func() {
+			defer close(waited)
+			c.Wait()
+		}()
+		select {
+		case <-waited:
+		case <-time.After(time.Second):
+			t.Fatalf("expected shutdown hook to not block the controller")
+		}
+		if fmt.Sprint(order) != "[shutdown finish]" {
+			t.Errorf("expected shutdown hooks before finish ones, got %v", order)
+		}
+	})
+	t.Run("Slow error hooks dont stall failing jobs", func(t *testing.T) {
+		c, _ := NewController()
+		release := make(chan struct{})
+		errs := []string{}
+		c.OnError(func(err error) {
+			<-release
+			errs = append(errs, errors.Unwrap(err).Error())
+		})
+		order := []string{}
+		c.OnFinish(func() { order = append(order, fmt.Sprint(len(errs))) })
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for _, name := range []string{"a", "b", "c"} {
+				c.BGo(newRunner(fmt.Errorf("%v", name)))
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Errorf("expected failing jobs to not wait for the error hooks")
+		}
+		close(release)
+		<-done
+		c.Wait()
+		if fmt.Sprint(errs) != "[a b c]" {
+			t.Errorf("expected the error hooks to be called in order, got %v", errs)
+		}
+		if fmt.Sprint(order) != "[3]" {
+			t.Errorf("expected finish hooks to wait for the error hooks, got %v", order)
+		}
+	})
+}
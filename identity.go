@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// jobIDs is used to give every job a unique id
+var jobIDs atomic.Uint64
+
+// Named can be implemented by a Runner to name its jobs (the `Name` option
+// takes precedence)
+type Named interface {
+	Name() string
+}
+
+// Label adds a key value label to the job, shown in `Job` and `JobError`
+func Label(key, value string) JobOption {
+	return func(j *job) {
+		if j.labels == nil {
+			j.labels = make(map[string]string)
+		}
+		j.labels[key] = value
+	}
+}
+
+// JobInfo identifies a job
+type JobInfo struct {
+	ID     uint64
+	Parent uint64 // ID of the job that submitted it (through its `rc`), 0 if none
+	Name   string // "" if not named
+	Labels map[string]string
+}
+
+// Job returns the info for the job being ran. ok is false if called outside a job
+func (c *Controller) Job() (info JobInfo, ok bool) {
+	if c.job == nil {
+		return JobInfo{}, false
+	}
+	return c.job.info(), true
+}
+
+// JobError is the error returned by a job along with which job it was
+type JobError struct {
+	JobInfo
+	Err error
+}
+
+// Error is prefixed with the name of the job, or its id and labels (i.e.
+// "job 42 [tenant=a]") if it isnt named
+func (e *JobError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%v: %v", e.Name, e.Err)
+	}
+	if len(e.Labels) == 0 {
+		return fmt.Sprintf("job %v: %v", e.ID, e.Err)
+	}
+	keys := make([]string, 0, len(e.Labels))
+	for key := range e.Labels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	labels := make([]string, len(keys))
+	for i, key := range keys {
+		labels[i] = key + "=" + e.Labels[key]
+	}
+	return fmt.Sprintf("job %v [%v]: %v", e.ID, strings.Join(labels, " "), e.Err)
+}
+
+// errorSummary is how err is listed in `Errors`. Errors from unnamed jobs
+// arent prefixed with their id so the list doesnt change depending on the
+// order jobs were submitted in
+func errorSummary(err error) string {
+	if e, ok := err.(*JobError); ok && e.Name == "" {
+		return e.Err.Error()
+	}
+	return err.Error()
+}
+
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+func (j *job) info() JobInfo {
+	return JobInfo{ID: j.id, Parent: j.parent, Name: j.name, Labels: maps.Clone(j.labels)}
+}
+
+// wrapErr returns the jobs error as a JobError, nil if there wasnt one
+func (j *job) wrapErr() error {
+	if j.err == nil {
+		return nil
+	}
+	return &JobError{JobInfo: j.info(), Err: j.err}
+}
+
+// String is used for logging
+func (j *job) String() string {
+	if j.name == "" {
+		return fmt.Sprintf("job %v", j.id)
+	}
+	return fmt.Sprintf("job %v (%v)", j.id, j.name)
+}
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// namedRunner implements Named
+type namedRunner struct {
+	name string
+	err  error
+}
+
+func (n namedRunner) Name() string             { return n.name }
+func (n namedRunner) Run(rc *Controller) error { return n.err }
+
+func TestJobIdentity(t *testing.T) {
+	t.Run("Jobs have unique ids, names and labels", func(t *testing.T) {
+		c, _ := NewController()
+		mu := sync.Mutex{}
+		infos := make(map[uint64]JobInfo)
+		job := RunnerFunc(func(rc *Controller) error {
+			info, ok := rc.Job()
+			if !ok {
+				return fmt.Errorf("expected job info")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			infos[info.ID] = info
+			return nil
+		})
+		c.Go(job, Name("first"), Label("tenant", "a"))
+		c.LimitedGo(job, Name("second"))
+		c.BGo(job)
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+		if len(infos) != 3 {
+			t.Errorf("expected 3 unique ids, got %v", len(infos))
+		}
+		for _, info := range infos {
+			if info.Name == "first" && info.Labels["tenant"] != "a" {
+				t.Errorf("expected tenant label, got %v", info.Labels)
+			}
+		}
+		if _, ok := c.Job(); ok {
+			t.Errorf("expected no job info outside a job")
+		}
+	})
+	t.Run("Errors include the job name", func(t *testing.T) {
+		c, _ := NewController()
+		errFailed := fmt.Errorf("failed")
+		c.Go(namedRunner{name: "from-interface", err: errFailed})
+		c.Wait()
+		if c.Errors() != "from-interface: failed" {
+			t.Errorf("expected named error, got %v", c.Errors())
+		}
+
+		c, _ = NewController()
+		c.Go(newRunner(errFailed))
+		c.Wait()
+		if c.Errors() != "failed" {
+			t.Errorf("expected unnamed error to be unchanged, got %v", c.Errors())
+		}
+	})
+	t.Run("Name option overrides Named", func(t *testing.T) {
+		c, _ := NewController()
+		p := c.Phase()
+		p.Go(namedRunner{name: "from-interface", err: fmt.Errorf("failed")}, Name("from-option"))
+		if err := p.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if p.Errors() != "from-option: failed" {
+			t.Errorf("expected named error, got %v", p.Errors())
+		}
+		c.Wait()
+	})
+	t.Run("JobError can be unwrapped", func(t *testing.T) {
+		errFailed := fmt.Errorf("failed")
+		var err error = &JobError{JobInfo: JobInfo{ID: 1, Name: "job"}, Err: errFailed}
+		if !errors.Is(err, errFailed) {
+			t.Errorf("expected errors.Is to find the job error")
+		}
+		var jobErr *JobError
+		if !errors.As(err, &jobErr) || jobErr.ID != 1 {
+			t.Errorf("expected errors.As to find the JobError")
+		}
+	})
+	t.Run("JobError without a name has the id and labels", func(t *testing.T) {
+		errFailed := fmt.Errorf("failed")
+		err := &JobError{JobInfo: JobInfo{ID: 42}, Err: errFailed}
+		if err.Error() != "job 42: failed" {
+			t.Errorf("expected the job id, got %v", err)
+		}
+		err.Labels = map[string]string{"tenant": "a", "region": "us"}
+		if err.Error() != "job 42 [region=us tenant=a]: failed" {
+			t.Errorf("expected the job id and labels, got %v", err)
+		}
+		err.Name = "named"
+		if err.Error() != "named: failed" {
+			t.Errorf("expected the job name, got %v", err)
+		}
+	})
+}
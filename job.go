@@ -0,0 +1,149 @@
+package runner
+
+import "time"
+
+// job is a Runner submitted to the controller and how it should be ran
+type job struct {
+	runner   Runner
+	id       uint64
+	parent   uint64 // id of the job that submitted it, 0 if none
+	name     string
+	labels   map[string]string
+	entry    string        // what it was submitted with, see `JobSnapshot`
+	requires []string      // names of `Background` jobs that have to be ready before starting
+	rateKey  string        // keyed rate limiter to wait for before starting, "" for none
+	priority int           // see `ShutdownPriority`
+	stop     chan struct{} // closed to stop a background job, only set if `WithOrderedShutdown`
+	exited   chan struct{} // closed when a background job with stop exits
+	internal bool          // see `internalRunner`
+	//-----Result------
+	skipped   bool
+	cancelled bool // see `DelayedJob.Cancel`
+	enqueued  time.Time
+	started   time.Time // set by the job registry
+	ended     time.Time // set by the job registry
+	err       error
+	onEnd     []func(j *job) // called once the job ran or was skipped
+	report    func(error)    // used instead of the controllers errors if set
+}
+
+// JobOption is used to configure a single job when it is submitted
+type JobOption func(j *job)
+
+// Name names the job, used by `Ready`, `Requires` and to identify it (see `Job`)
+func Name(name string) JobOption {
+	return func(j *job) {
+		j.name = name
+	}
+}
+
+// Requires makes the job wait until the `Background` jobs named names are
+// ready (see `Ready`) before starting (limited jobs wait before taking a
+// slot). If one isnt, the job is skipped
+func Requires(names ...string) JobOption {
+	return func(j *job) {
+		j.requires = append(j.requires, names...)
+	}
+}
+
+// RateKey makes the job wait for the rate limiter registered with
+// `WithKeyedRateLimit` under key before starting (as well as the global one)
+func RateKey(key string) JobOption {
+	return func(j *job) {
+		j.rateKey = key
+	}
+}
+
+// whenEnded calls fn once the job ran or was skipped, before the count for it
+// goes down (so before `Wait` can return)
+func whenEnded(fn func(j *job)) JobOption {
+	return func(j *job) {
+		j.onEnd = append(j.onEnd, fn)
+	}
+}
+
+// internalRunner marks the job as the libraries own wrapper (i.e. the
+// supervisor) so middleware isnt applied to it, only to the runners it runs
+func internalRunner() JobOption {
+	return func(j *job) {
+		j.internal = true
+	}
+}
+
+// reportErrors sends errors from the job to fn instead of the controller
+func reportErrors(fn func(err error)) JobOption {
+	return func(j *job) {
+		j.report = fn
+	}
+}
+
+// newJob creates a job submitted with entry, from inside the job c is for (if any)
+func (c *Controller) newJob(entry string, runner Runner, opts []JobOption) *job {
+	j := &job{runner: runner, id: jobIDs.Add(1), entry: entry, enqueued: time.Now()}
+	if c.job != nil {
+		j.parent = c.job.id
+	}
+	if named, ok := runner.(Named); ok {
+		j.name = named.Name()
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if !j.internal {
+		j.runner = c.middlewares.wrap(runner)
+	}
+	return j
+}
+
+// runJob runs the job once it is allowed to start. If shutdown before, it will
+// skip running the job and return true (false means it ran the job)
+func (c *Controller) runJob(j *job, w *worker) bool {
+	if !c.waitForStart(j) {
+		return true
+	}
+	return c.runStarted(j, w)
+}
+
+// waitForStart waits until j is allowed to start (what it requires is ready
+// and the rate limit), limited jobs wait for it before taking a slot. If
+// shutdown before, it will skip the job and return false
+func (c *Controller) waitForStart(j *job) bool {
+	if !c.waitForRequired(j) || !c.waitForRate(j.rateKey) {
+		c.debugJob(j, "Not running because shuting down")
+		c.skipJob(j)
+		return false
+	}
+	return true
+}
+
+// runStarted is the same as runJob once `waitForStart` returned true
+func (c *Controller) runStarted(j *job, w *worker) bool {
+	c.debugJob(j, "Starting")
+	c.jobs.start(j)
+	j.err = j.runner.Run(c.scoped(j, w))
+	c.jobs.end(j)
+	if j.err == nil || j.err == ErrShuttingDown {
+		return false
+	}
+	c.debugJob(j, "Failed", "error", j.err)
+	if j.report == nil {
+		c.addError(j.wrapErr())
+	} else {
+		j.report(j.wrapErr())
+	}
+	return false
+}
+
+// skipJob marks j as never ran because the controller is shutting down
+func (c *Controller) skipJob(j *job) {
+	j.skipped = true
+	c.skippedCount.Add(1)
+}
+
+// endJob is called once j ran or was skipped
+func (c *Controller) endJob(j *job) {
+	c.jobs.done(j)
+	for _, fn := range j.onEnd {
+		fn(j)
+	}
+}
@@ -0,0 +1,225 @@
+package runner
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+const defaultJobHistory = 100
+
+// JobState is where a job is in its life
+type JobState int
+
+const (
+	JobPending JobState = iota // submitted but hasnt started (i.e. waiting for the limiter)
+	JobRunning
+	JobSucceeded
+	JobFailed
+	JobSkipped   // never ran because shutting down (or draining)
+	JobCancelled // a delayed job that was cancelled (see `DelayedJob.Cancel`)
+)
+
+func (s JobState) String() string {
+	switch s {
+	case JobPending:
+		return "pending"
+	case JobRunning:
+		return "running"
+	case JobSucceeded:
+		return "succeeded"
+	case JobFailed:
+		return "failed"
+	case JobSkipped:
+		return "skipped"
+	case JobCancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("JobState(%d)", int(s))
+}
+
+// JobSnapshot is the state of a job when `Jobs` was called
+type JobSnapshot struct {
+	JobInfo
+	Entry    string // what it was submitted with (i.e. "Go", "LimitedGo", "Background")
+	State    JobState
+	Enqueued time.Time
+	Started  time.Time // zero if it hasnt started
+	Ended    time.Time // zero if it hasnt ended
+	// Elapsed is how long it has been waiting if pending, running if running
+	// or how long it ran for once ended
+	Elapsed time.Duration
+	Err     error
+}
+
+// WithJobHistory changes how many ended jobs are kept for `Jobs` (defaults to
+// 100), 0 keeps none
+func WithJobHistory(size int) Option {
+	return func(c *Controller) error {
+		c.jobs.size = max(size, 0)
+		return nil
+	}
+}
+
+// jobRegistry keeps track of the jobs that havent ended and the most recent
+// ones that have
+type jobRegistry struct {
+	mu      sync.Mutex
+	active  map[uint64]*job
+	history []JobSnapshot // ring buffer of ended jobs, next is the oldest once full
+	next    int
+	size    int
+	stats   jobStats
+	tracer  Tracer // nil unless `WithTracer`
+	hooks   *hooks
+	parent  *jobRegistry // also tracks the jobs (see `Child`), nil if none
+}
+
+func newJobRegistry(h *hooks) *jobRegistry {
+	return &jobRegistry{active: make(map[uint64]*job), size: defaultJobHistory, stats: newJobStats(), hooks: h}
+}
+
+// lock locks r and its parents, so j can be changed while they track it too
+func (r *jobRegistry) lock() {
+	for p := r; p != nil; p = p.parent {
+		p.mu.Lock()
+	}
+}
+
+func (r *jobRegistry) unlock() {
+	for p := r; p != nil; p = p.parent {
+		p.mu.Unlock()
+	}
+}
+
+// add starts tracking j once it is submitted
+func (r *jobRegistry) add(j *job) {
+	r.lock()
+	for p := r; p != nil; p = p.parent {
+		p.active[j.id] = j
+		p.stats.entry(j.entry).Submitted++
+	}
+	s := r.snapshot(j, time.Now())
+	r.unlock()
+
+	if r.tracer != nil {
+		r.tracer.JobEnqueued(s)
+	}
+}
+
+// start is called right before j runs
+func (r *jobRegistry) start(j *job) {
+	r.lock()
+	j.started = time.Now()
+	for p := r; p != nil; p = p.parent {
+		p.stats.entry(j.entry).Started++
+		p.stats.queueWait.observe(j.started.Sub(j.enqueued))
+	}
+	s := r.snapshot(j, j.started)
+	r.unlock()
+
+	if r.tracer != nil {
+		r.tracer.JobStarted(s)
+	}
+	r.hooks.jobStarted(s)
+}
+
+// end is called right after j ran
+func (r *jobRegistry) end(j *job) {
+	r.lock()
+	defer r.unlock()
+	j.ended = time.Now()
+	for p := r; p != nil; p = p.parent {
+		p.stats.runTime.observe(j.ended.Sub(j.started))
+	}
+}
+
+// done moves j to the history once it ran or was skipped
+func (r *jobRegistry) done(j *job) {
+	r.lock()
+	s := r.snapshot(j, time.Now())
+	switch {
+	case j.skipped:
+		s.State = JobSkipped
+	case j.cancelled:
+		s.State = JobCancelled
+	case j.err != nil && j.err != ErrShuttingDown:
+		s.State = JobFailed
+		s.Err = j.err
+	}
+	for p := r; p != nil; p = p.parent {
+		delete(p.active, j.id)
+		switch s.State {
+		case JobSkipped:
+			p.stats.entry(j.entry).Skipped++
+		case JobCancelled:
+			p.stats.entry(j.entry).Cancelled++
+		case JobFailed:
+			p.stats.entry(j.entry).Failed++
+		default:
+			p.stats.entry(j.entry).Succeeded++
+		}
+		p.remember(s)
+	}
+	r.unlock()
+
+	if r.tracer != nil {
+		r.tracer.JobEnded(s)
+	}
+	r.hooks.jobEnded(s)
+}
+
+// remember adds s to the history, should be called with the lock held
+func (r *jobRegistry) remember(s JobSnapshot) {
+	if r.size == 0 {
+		return
+	}
+	if len(r.history) < r.size {
+		r.history = append(r.history, s)
+		return
+	}
+	r.history[r.next] = s
+	r.next = (r.next + 1) % r.size
+}
+
+// snapshot should be called with the lock held
+func (r *jobRegistry) snapshot(j *job, now time.Time) JobSnapshot {
+	s := JobSnapshot{
+		JobInfo:  j.info(),
+		Entry:    j.entry,
+		Enqueued: j.enqueued,
+		Started:  j.started,
+		Ended:    j.ended,
+	}
+	switch {
+	case j.started.IsZero():
+		s.State = JobPending
+		s.Elapsed = now.Sub(j.enqueued)
+	case j.ended.IsZero():
+		s.State = JobRunning
+		s.Elapsed = now.Sub(j.started)
+	default:
+		s.State = JobSucceeded
+		s.Elapsed = j.ended.Sub(j.started)
+	}
+	return s
+}
+
+// Jobs returns a snapshot of every pending and running job (oldest first)
+// followed by the most recently ended ones (see `WithJobHistory`)
+func (c *Controller) Jobs() []JobSnapshot {
+	c.jobs.mu.Lock()
+	defer c.jobs.mu.Unlock()
+	now := time.Now()
+	snapshots := make([]JobSnapshot, 0, len(c.jobs.active)+len(c.jobs.history))
+	for _, j := range c.jobs.active {
+		snapshots = append(snapshots, c.jobs.snapshot(j, now))
+	}
+	slices.SortFunc(snapshots, func(a, b JobSnapshot) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	snapshots = append(snapshots, c.jobs.history[c.jobs.next:]...)
+	return append(snapshots, c.jobs.history[:c.jobs.next]...)
+}
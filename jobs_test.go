@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestJobs(t *testing.T) {
+	t.Run("Shows pending, running and ended jobs", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(1)
+		release := make(chan struct{})
+		started := make(chan struct{})
+		bgStarted := make(chan struct{})
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			close(bgStarted)
+			<-rc.ShuttingDownChan()
+			return nil
+		}), Name("background"))
+		<-bgStarted
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			close(started)
+			<-release
+			return nil
+		}), Name("running"))
+		<-started
+		c.LimitedGo(newRunner(nil), Name("pending"))
+
+		states := map[string]JobState{}
+		entries := map[string]string{}
+		for _, s := range c.Jobs() {
+			states[s.Name] = s.State
+			entries[s.Name] = s.Entry
+		}
+		expected := map[string]JobState{"background": JobRunning, "running": JobRunning, "pending": JobPending}
+		for name, state := range expected {
+			if states[name] != state {
+				t.Errorf("expected %v to be %v, got %v", name, state, states[name])
+			}
+		}
+		if entries["background"] != "Background" || entries["pending"] != "LimitedGo" {
+			t.Errorf("expected entry points, got %v", entries)
+		}
+
+		close(release)
+		c.Go(newRunner(fmt.Errorf("failed")), Name("failed"))
+		c.Wait()
+		c.Go(newRunner(nil), Name("skipped"))
+
+		states = map[string]JobState{}
+		for _, s := range c.Jobs() {
+			states[s.Name] = s.State
+			if s.State == JobFailed && s.Err == nil {
+				t.Errorf("expected failed job to have an error")
+			}
+			if s.State == JobSucceeded && s.Elapsed <= 0 {
+				t.Errorf("expected elapsed for %v, got %v", s.Name, s.Elapsed)
+			}
+		}
+		expected = map[string]JobState{"background": JobSucceeded, "running": JobSucceeded, "pending": JobSucceeded, "failed": JobFailed, "skipped": JobSkipped}
+		for name, state := range expected {
+			if states[name] != state {
+				t.Errorf("expected %v to be %v, got %v", name, state, states[name])
+			}
+		}
+	})
+	t.Run("Keeps the most recent history", func(t *testing.T) {
+		c, _ := NewController(WithJobHistory(3))
+		for i := range 5 {
+			c.BGo(newRunner(nil), Name(fmt.Sprint(i)))
+		}
+		c.Wait()
+		jobs := c.Jobs()
+		if len(jobs) != 3 {
+			t.Errorf("expected 3 jobs, got %v", len(jobs))
+		}
+		for i, s := range jobs {
+			if s.Name != fmt.Sprint(i+2) {
+				t.Errorf("expected job %v, got %v", i+2, s.Name)
+			}
+		}
+	})
+	t.Run("Pending jobs elapsed is time waiting", func(t *testing.T) {
+		c, _ := NewController()
+		c.GoAfter(time.Hour, newRunner(nil))
+		time.Sleep(time.Millisecond)
+		jobs := c.Jobs()
+		if len(jobs) != 1 || jobs[0].State != JobPending || jobs[0].Entry != "GoAt" || jobs[0].Elapsed < time.Millisecond {
+			t.Errorf("expected a pending job, got %+v", jobs)
+		}
+		c.Shutdown()
+		c.Wait()
+	})
+}
@@ -0,0 +1,87 @@
+package runner
+
+// GoKeyed is the same as `Go` but jobs with the same key run one at a time in
+// the order they were submitted, jobs with different keys run in parallel.
+// If shutting down, jobs still waiting on their key are skipped
+func (c *Controller) GoKeyed(key string, runner Runner, opts ...JobOption) {
+	j := c.newJob("GoKeyed", runner, opts)
+	c.addCount(c.mainCountChan, j, func(finished func()) {
+		prev, next := c.queueKey(key)
+		go c.withLabels(j, func() {
+			defer finished()
+			defer c.releaseKey(key, next)
+			if c.waitForKey(j, prev) {
+				c.runJob(j, nil)
+			}
+		})
+	})
+}
+
+// LimitedGoKeyed is the same as `LimitedGo` but jobs with the same key run one
+// at a time in the order they were submitted. A job doesnt take up room in the
+// limiter while waiting on its key
+func (c *Controller) LimitedGoKeyed(key string, runner Runner, opts ...JobOption) {
+	j := c.newJob("LimitedGoKeyed", runner, opts)
+	c.addCount(c.limitCountChan, j, func(finished func()) {
+		prev, next := c.queueKey(key)
+		go c.withLabels(j, func() {
+			if !c.waitForKey(j, prev) {
+				c.releaseKey(key, next)
+				finished()
+				return
+			}
+			if c.pool != nil {
+				c.queueJob(j, func() {
+					c.releaseKey(key, next)
+					finished()
+				}, nil)
+				return
+			}
+			defer finished()
+			defer c.releaseKey(key, next)
+			c.runLimited(j)
+		})
+	})
+}
+
+// queueKey returns a channel that will be closed when the previous job with
+// key is finished, and the channel to close once this one is finished
+func (c *Controller) queueKey(key string) (prev chan struct{}, next chan struct{}) {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	prev, ok := c.keys[key]
+	if !ok {
+		prev = make(chan struct{})
+		close(prev)
+	}
+	next = make(chan struct{})
+	c.keys[key] = next
+	return prev, next
+}
+
+// releaseKey lets the next job with key run
+func (c *Controller) releaseKey(key string, next chan struct{}) {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	close(next)
+	if c.keys[key] == next {
+		delete(c.keys, key) // nothing else waiting on this key
+	}
+}
+
+// waitForKey waits for the previous job with the same key to finish. If
+// shutdown before, it returns false
+func (c *Controller) waitForKey(j *job, prev chan struct{}) bool {
+	select {
+	case <-c.doneChan:
+	case <-prev:
+		select { // both could be closed, select picks either
+		case <-c.doneChan:
+		default:
+			return true
+		}
+	}
+	log.Debugf("Not running keyed %v because shuting down", j)
+	c.skipJob(j)
+	return false
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyed(t *testing.T) {
+	t.Run("Same key runs in order one at a time", func(t *testing.T) {
+		for _, limited := range []bool{false, true} {
+			c, _ := NewControllerWithLimit(4)
+			mu := sync.Mutex{}
+			order := map[string][]int{}
+			running := map[string]int{}
+			for i := 0; i < 20; i++ {
+				key := fmt.Sprintf("account-%v", i%2)
+				job := RunnerFunc(func(rc *Controller) error {
+					mu.Lock()
+					running[key]++
+					if running[key] > 1 {
+						mu.Unlock()
+						return fmt.Errorf("%v ran at the same time", key)
+					}
+					order[key] = append(order[key], i)
+					mu.Unlock()
+
+					time.Sleep(time.Millisecond)
+
+					mu.Lock()
+					running[key]--
+					mu.Unlock()
+					return nil
+				})
+				if limited {
+					c.LimitedGoKeyed(key, job)
+				} else {
+					c.GoKeyed(key, job)
+				}
+			}
+			if err := c.Wait(); err != nil {
+				t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+			}
+			if !slices.Equal(order["account-0"], []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}) {
+				t.Errorf("expected account-0 in order, got %v", order["account-0"])
+			}
+			if !slices.Equal(order["account-1"], []int{1, 3, 5, 7, 9, 11, 13, 15, 17, 19}) {
+				t.Errorf("expected account-1 in order, got %v", order["account-1"])
+			}
+		}
+	})
+	t.Run("Different keys run in parallel", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(2, WithWorkerPool(nil))
+		rec := newRecieverRunnner(1)
+		c.LimitedGoKeyed("foo", rec)
+		rec.started()
+		c.LimitedGoKeyed("bar", rec.newSenderRunnner(1))
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+	})
+	t.Run("Queued jobs are skipped when shutting down", func(t *testing.T) {
+		c, _ := NewController()
+		rec := newRecieverRunnner(1)
+		c.GoKeyed("foo", RunnerFunc(func(rc *Controller) error {
+			rec.Run(rc)
+			return nil
+		}))
+		rec.started()
+		ran := false
+		c.GoKeyed("foo", RunnerFunc(func(rc *Controller) error {
+			ran = true
+			return nil
+		}))
+		c.LimitedGoKeyed("foo", RunnerFunc(func(rc *Controller) error {
+			ran = true
+			return nil
+		}))
+		c.Shutdown()
+		c.Wait()
+		if ran {
+			t.Errorf("expected queued jobs to be skipped")
+		}
+		if c.Skipped() != 2 {
+			t.Errorf("expected 2 jobs to be skipped, got %v", c.Skipped())
+		}
+	})
+	t.Run("Skipped if shutting down when the previous job finishes", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			c, _ := NewController()
+			prev := make(chan struct{})
+			close(prev)
+			c.Shutdown()
+			j := c.newJob("GoKeyed", newRunner(nil), nil)
+			if c.waitForKey(j, prev) {
+				t.Fatalf("expected job to be skipped when shutting down")
+			}
+			c.Wait()
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package runner
+
+import "sync"
+
+// limiter is a semaphore used to limit the number of concurrent limited jobs.
+// Unlike a buffered channel the limit can be changed while jobs are running
+type limiter struct {
+	mu      sync.Mutex
+	limit   int
+	inUse   int
+	waiters []chan struct{} // closed when given a slot, first in first out
+	full    bool            // true if a slot was wanted while all were in use
+	paused  bool            // no slots are given out while paused
+	parent  *limiter        // a slot is also taken from it (see `Child`), nil if none
+}
+
+func newLimiter(limit int) *limiter {
+	return &limiter{limit: limit}
+}
+
+// acquire waits for a free slot (and one from the parent). If done is closed
+// before, it returns false
+func (l *limiter) acquire(done <-chan struct{}) bool {
+	if !l.acquireOwn(done) {
+		return false
+	}
+	if l.parent != nil && !l.parent.acquire(done) {
+		l.releaseOwn()
+		return false
+	}
+	return true
+}
+
+// acquireOwn waits for a free slot without taking one from the parent
+func (l *limiter) acquireOwn(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return false
+	default:
+	}
+
+	l.mu.Lock()
+	if l.inUse < l.limit && len(l.waiters) == 0 && !l.paused {
+		l.inUse++
+		l.full = l.full || l.inUse == l.limit
+		l.mu.Unlock()
+		return true
+	}
+	l.full = l.full || !l.paused
+	ch := make(chan struct{})
+	l.waiters = append(l.waiters, ch)
+	l.mu.Unlock()
+
+	select {
+	case <-ch:
+		return true
+	case <-done:
+	}
+
+	l.mu.Lock()
+	for i, w := range l.waiters {
+		if w == ch {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			l.mu.Unlock()
+			return false
+		}
+	}
+	// already given a slot, so give it back
+	l.mu.Unlock()
+	l.releaseOwn()
+	return false
+}
+
+// release gives back a slot (and the parents), returns false if there was
+// nothing to release
+func (l *limiter) release() bool {
+	if !l.releaseOwn() {
+		return false
+	}
+	if l.parent != nil {
+		l.parent.release()
+	}
+	return true
+}
+
+// releaseOwn gives back a slot without giving back the parents
+func (l *limiter) releaseOwn() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inUse == 0 {
+		return false
+	}
+	l.inUse--
+	l.grant()
+	return true
+}
+
+// setLimit changes the limit, if lowered running jobs are not stopped but no
+// new ones will start until under the new limit
+func (l *limiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.grant()
+}
+
+// setPaused stops (or starts again) giving out slots, running jobs keep theirs
+func (l *limiter) setPaused(paused bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.paused = paused
+	l.grant()
+}
+
+// grant hands out free slots to waiters, should be called with the lock held
+func (l *limiter) grant() {
+	for !l.paused && l.inUse < l.limit && len(l.waiters) > 0 {
+		close(l.waiters[0])
+		l.waiters[0] = nil
+		l.waiters = l.waiters[1:]
+		l.inUse++
+	}
+}
+
+// usage returns the current limit, slots in use and number waiting
+func (l *limiter) usage() (limit, inUse, waiting int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.inUse, len(l.waiters)
+}
+
+// wasFull returns if a slot was wanted while all were in use since last called
+func (l *limiter) wasFull() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	full := l.full
+	l.full = l.inUse >= l.limit
+	return full
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MetricsHandler returns a http.Handler that renders `Stats` (and if shutting
+// down, draining or paused) in the Prometheus text format
+func (c *Controller) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.writeMetrics(w)
+	})
+}
+
+func (c *Controller) writeMetrics(out io.Writer) {
+	stats := c.Stats()
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	entryCounter := func(name, help string, value func(e EntryStats) int64) {
+		metricHeader(w, name, "counter", help)
+		for _, entry := range stats.EntryNames() {
+			fmt.Fprintf(w, "%v{entry=%q} %v\n", name, entry, value(stats.Entries[entry]))
+		}
+	}
+	entryCounter("runner_jobs_submitted_total", "Jobs submitted.", func(e EntryStats) int64 { return e.Submitted })
+	entryCounter("runner_jobs_started_total", "Jobs started.", func(e EntryStats) int64 { return e.Started })
+	entryCounter("runner_jobs_succeeded_total", "Jobs that returned no error.", func(e EntryStats) int64 { return e.Succeeded })
+	entryCounter("runner_jobs_failed_total", "Jobs that returned an error.", func(e EntryStats) int64 { return e.Failed })
+	entryCounter("runner_jobs_skipped_total", "Jobs that never ran because shutting down.", func(e EntryStats) int64 { return e.Skipped })
+	entryCounter("runner_jobs_cancelled_total", "Delayed jobs that were cancelled.", func(e EntryStats) int64 { return e.Cancelled })
+
+	gauge := func(name, help string, value int) {
+		metricHeader(w, name, "gauge", help)
+		fmt.Fprintf(w, "%v %v\n", name, value)
+	}
+	gauge("runner_limit", "Limited jobs that can run at a time.", stats.Limit)
+	gauge("runner_limiter_in_use", "Limited jobs running.", stats.InUse)
+	gauge("runner_limiter_waiting", "Limited jobs waiting for the limiter.", stats.Waiting)
+	gauge("runner_queue_depth", "Limited jobs waiting for a worker.", stats.Queued)
+	gauge("runner_shutting_down", "1 if the controller is shutting down.", boolToInt(c.IsShuttingDown()))
+	gauge("runner_draining", "1 if the controller is draining.", boolToInt(c.IsDraining()))
+	gauge("runner_paused", "1 if the controller is paused.", boolToInt(c.IsPaused()))
+
+	writeHistogram(w, "runner_job_queue_wait_seconds", "Time from being submitted to starting.", stats.QueueWait)
+	writeHistogram(w, "runner_job_run_seconds", "Time jobs ran for.", stats.RunTime)
+}
+
+func metricHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+}
+
+func writeHistogram(w io.Writer, name, help string, h Histogram) {
+	metricHeader(w, name, "histogram", help)
+	cumulative := int64(0)
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		fmt.Fprintf(w, "%v_bucket{le=\"%v\"} %v\n", name, seconds(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%v_bucket{le=\"+Inf\"} %v\n", name, h.Count)
+	fmt.Fprintf(w, "%v_sum %v\n", name, seconds(h.Sum))
+	fmt.Fprintf(w, "%v_count %v\n", name, h.Count)
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
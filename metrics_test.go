@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	t.Run("Renders prometheus text", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(3)
+		c.Go(newRunner(nil))
+		c.LimitedGo(newRunner(fmt.Errorf("failed")))
+		c.Wait()
+
+		rec := httptest.NewRecorder()
+		c.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+			t.Errorf("expected prometheus content type, got %v", ct)
+		}
+		body := rec.Body.String()
+		for _, line := range []string{
+			"# TYPE runner_jobs_submitted_total counter",
+			`runner_jobs_submitted_total{entry="Go"} 1`,
+			`runner_jobs_failed_total{entry="LimitedGo"} 1`,
+			`runner_jobs_succeeded_total{entry="Go"} 1`,
+			"runner_limit 3",
+			"runner_shutting_down 1",
+			"runner_paused 0",
+			"# TYPE runner_job_run_seconds histogram",
+			`runner_job_run_seconds_bucket{le="0.001"} 2`,
+			`runner_job_run_seconds_bucket{le="+Inf"} 2`,
+			"runner_job_run_seconds_count 2",
+		} {
+			if !strings.Contains(body, line+"\n") {
+				t.Errorf("expected %q in:\n%v", line, body)
+			}
+		}
+	})
+}
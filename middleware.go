@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrPanic = fmt.Errorf("job panicked")
+
+// RunnerFunc lets a function be used as a Runner
+type RunnerFunc func(rc *Controller) error
+
+func (f RunnerFunc) Run(rc *Controller) error {
+	return f(rc)
+}
+
+// Middleware wraps a Runner with another, see `Use`. (`CircuitBreaker.Wrap`
+// can be used as one)
+type Middleware func(next Runner) Runner
+
+// middlewares are the Middleware added with `Use`
+type middlewares struct {
+	mu  sync.Mutex
+	all []Middleware
+}
+
+// Use wraps every job submitted after with mw (i.e. `Go`, `LimitedGo`,
+// `Background`...). The first Middleware added is the outermost one. Child
+// controllers start with the Middleware of their parent
+func (c *Controller) Use(mw ...Middleware) {
+	c.middlewares.mu.Lock()
+	defer c.middlewares.mu.Unlock()
+	c.middlewares.all = append(c.middlewares.all, mw...)
+}
+
+// wrap returns r wrapped with all of the Middleware
+func (m *middlewares) wrap(r Runner) Runner {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.all) - 1; i >= 0; i-- {
+		r = m.all[i](r)
+	}
+	return r
+}
+
+func (m *middlewares) clone() *middlewares {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &middlewares{all: slices.Clone(m.all)}
+}
+
+// Logging logs when jobs start and end (using `SetLogger`)
+func Logging() Middleware {
+	return func(next Runner) Runner {
+		return RunnerFunc(func(rc *Controller) error {
+			name := jobName(rc)
+			log.Infof("Starting %v", name)
+			start := time.Now()
+			err := next.Run(rc)
+			if err != nil {
+				log.Errorf("%v failed after %v: %v", name, time.Since(start), err)
+			} else {
+				log.Infof("%v finished after %v", name, time.Since(start))
+			}
+			return err
+		})
+	}
+}
+
+// Timing calls fn with how long each job ran and its error
+func Timing(fn func(info JobInfo, elapsed time.Duration, err error)) Middleware {
+	return func(next Runner) Runner {
+		return RunnerFunc(func(rc *Controller) error {
+			start := time.Now()
+			err := next.Run(rc)
+			info, _ := rc.Job()
+			fn(info, time.Since(start), err)
+			return err
+		})
+	}
+}
+
+// Recover turns panics in jobs into ErrPanic errors instead of crashing
+func Recover() Middleware {
+	return func(next Runner) Runner {
+		return RunnerFunc(func(rc *Controller) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorf("%v panicked: %v\n%s", jobName(rc), r, debug.Stack())
+					err = fmt.Errorf("%w: %v", ErrPanic, r)
+				}
+			}()
+			return next.Run(rc)
+		})
+	}
+}
+
+// Retry runs jobs up to attempts times until they dont return an error,
+// waiting backoff before the first retry and doubling it after each. It
+// stops retrying if shutting down
+func Retry(attempts int, backoff time.Duration) Middleware {
+	return func(next Runner) Runner {
+		return RunnerFunc(func(rc *Controller) error {
+			wait := backoff
+			for attempt := 1; ; attempt++ {
+				err := next.Run(rc)
+				if err == nil || err == ErrShuttingDown || attempt >= attempts {
+					return err
+				}
+				log.Debugf("Retrying %v after %v: %v", jobName(rc), wait, err)
+				if !sleep(rc, wait) {
+					return err
+				}
+				wait *= 2
+			}
+		})
+	}
+}
+
+// sleep waits for d, returns false if shutting down first
+func sleep(rc *Controller, d time.Duration) bool {
+	if d <= 0 {
+		return !rc.IsShuttingDown()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-rc.ShuttingDownChan():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// jobName is used for logging from middleware
+func jobName(rc *Controller) string {
+	if rc.job == nil {
+		return "job"
+	}
+	return rc.job.String()
+}
@@ -0,0 +1,168 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingLogger keeps info and error logs
+type recordingLogger struct {
+	emptyLogger
+	mu   sync.Mutex
+	logs []string
+}
+
+func (r *recordingLogger) Infof(f string, x ...interface{})  { r.add(fmt.Sprintf(f, x...)) }
+func (r *recordingLogger) Errorf(f string, x ...interface{}) { r.add(fmt.Sprintf(f, x...)) }
+func (r *recordingLogger) add(s string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, s)
+}
+
+// tag is a middleware that records when it runs
+func tag(name string, called *[]string) Middleware {
+	return func(next Runner) Runner {
+		return RunnerFunc(func(rc *Controller) error {
+			*called = append(*called, name)
+			return next.Run(rc)
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("Wraps every job in order", func(t *testing.T) {
+		c, _ := NewController()
+		called := []string{}
+		c.Use(tag("first", &called), tag("second", &called))
+		c.BGo(RunnerFunc(func(rc *Controller) error {
+			called = append(called, "job")
+			return nil
+		}))
+		c.Wait()
+		if fmt.Sprint(called) != "[first second job]" {
+			t.Errorf("expected [first second job], got %v", called)
+		}
+	})
+	t.Run("Applies to every entry point and children", func(t *testing.T) {
+		c, _ := NewController()
+		count := atomic.Int32{}
+		c.Use(func(next Runner) Runner {
+			return RunnerFunc(func(rc *Controller) error {
+				count.Add(1)
+				return next.Run(rc)
+			})
+		})
+		c.Go(newRunner(nil))
+		c.BGo(newRunner(nil))
+		c.LimitedGo(newRunner(nil))
+		c.BlLimitedGo(newRunner(nil))
+		c.Background(newRunner(nil))
+		child, _ := c.Child()
+		child.Go(newRunner(nil))
+		child.Wait()
+		c.Wait()
+		if count.Load() != 6 {
+			t.Errorf("expected 6 wrapped jobs, got %v", count.Load())
+		}
+	})
+	t.Run("Recover turns panics into errors", func(t *testing.T) {
+		c, _ := NewController()
+		c.Use(Recover())
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			panic("oops")
+		}))
+		if err := c.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if c.Errors() != "job panicked: oops" {
+			t.Errorf("expected panic error, got %v", c.Errors())
+		}
+	})
+	t.Run("Retry until success or out of attempts", func(t *testing.T) {
+		c, _ := NewController()
+		c.Use(Retry(3, time.Millisecond))
+		flaky := atomic.Int32{}
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			if flaky.Add(1) < 2 {
+				return fmt.Errorf("flaky")
+			}
+			return nil
+		}))
+		failing := atomic.Int32{}
+		errFailed := fmt.Errorf("failed")
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			failing.Add(1)
+			return errFailed
+		}))
+		c.Wait()
+		if flaky.Load() != 2 || failing.Load() != 3 {
+			t.Errorf("expected 2 and 3 attempts, got %v and %v", flaky.Load(), failing.Load())
+		}
+		if c.Errors() != "failed" {
+			t.Errorf("expected one failed error, got %v", c.Errors())
+		}
+	})
+	t.Run("Timing and logging", func(t *testing.T) {
+		logger := &recordingLogger{}
+		SetLogger(logger)
+		defer SetLogger(emptyLogger{})
+
+		c, _ := NewController()
+		var timed JobInfo
+		var timedErr error
+		c.Use(Logging(), Timing(func(info JobInfo, elapsed time.Duration, err error) {
+			timed, timedErr = info, err
+		}))
+		errFailed := fmt.Errorf("failed")
+		c.BGo(newRunner(errFailed), Name("timed"))
+		c.Wait()
+		if timed.Name != "timed" || !errors.Is(timedErr, errFailed) {
+			t.Errorf("expected timing for the job, got %v %v", timed, timedErr)
+		}
+		logs := strings.Join(logger.logs, "\n")
+		if !strings.Contains(logs, "Starting job") || !strings.Contains(logs, "(timed) failed after") {
+			t.Errorf("expected start and end logs, got %v", logs)
+		}
+	})
+	t.Run("Internal runners arent wrapped", func(t *testing.T) {
+		c, _ := NewController()
+		c.Use(Retry(3, 0))
+		runs := atomic.Int32{}
+		c.Supervise(SupervisorConfig{MaxRestarts: 1}, RunnerFunc(func(rc *Controller) error {
+			runs.Add(1)
+			return fmt.Errorf("failed")
+		}))
+		c.Go(foreverRunnner{})
+		c.Wait()
+		if runs.Load() != 6 { // 3 attempts for the first run and the restart
+			t.Errorf("expected 6 runs, got %v", runs.Load())
+		}
+
+		c, _ = NewController()
+		timed, others := atomic.Int32{}, atomic.Int32{}
+		c.Use(Timing(func(info JobInfo, elapsed time.Duration, err error) {
+			if info.Name == "tick" {
+				timed.Add(1)
+			} else {
+				others.Add(1)
+			}
+		}))
+		c.Every(time.Millisecond, namedRunner{name: "tick"}, ScheduleConfig{NoOverlap: true})
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			for timed.Load() < 2 {
+				time.Sleep(time.Millisecond)
+			}
+			return nil
+		}))
+		c.Wait()
+		if others.Load() != 1 { // only the Go job, not the schedule loop
+			t.Errorf("expected only 1 other job to be timed, got %v", others.Load())
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package runner
+
+import "sync"
+
+// pauseState keeps track of if the controller is paused
+type pauseState struct {
+	mu      sync.Mutex
+	paused  chan struct{} // closed while paused
+	resumed chan struct{} // closed while not paused
+}
+
+func newPauseState() *pauseState {
+	resumed := make(chan struct{})
+	close(resumed)
+	return &pauseState{paused: make(chan struct{}), resumed: resumed}
+}
+
+// Pause stops limited jobs (`LimitedGo` and `BlLimitedGo`) from starting
+// until `Resume` is called, they stay queued. Jobs that are already running
+// keep running, long running ones can check `PausedChan` at safe points
+func (c *Controller) Pause() {
+	c.pause.mu.Lock()
+	defer c.pause.mu.Unlock()
+	select {
+	case <-c.pause.paused:
+		return // already paused
+	default:
+	}
+	log.Info("Pausing...")
+	close(c.pause.paused)
+	c.pause.resumed = make(chan struct{})
+	c.limiter.setPaused(true)
+}
+
+// Resume lets limited jobs start again after `Pause`
+func (c *Controller) Resume() {
+	c.pause.mu.Lock()
+	defer c.pause.mu.Unlock()
+	select {
+	case <-c.pause.resumed:
+		return // not paused
+	default:
+	}
+	log.Info("Resuming...")
+	close(c.pause.resumed)
+	c.pause.paused = make(chan struct{})
+	c.limiter.setPaused(false)
+}
+
+// PausedChan will return a channel that will be closed when the controller is
+// paused. It should be called again after resuming
+func (c *Controller) PausedChan() <-chan struct{} {
+	c.pause.mu.Lock()
+	defer c.pause.mu.Unlock()
+	return c.pause.paused
+}
+
+// IsPaused will return true if the controller is paused
+func (c *Controller) IsPaused() bool {
+	select {
+	case <-c.PausedChan():
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitResumed waits until the controller isnt paused. Returns ErrShuttingDown
+// if shutting down first
+func (c *Controller) WaitResumed() error {
+	c.pause.mu.Lock()
+	resumed := c.pause.resumed
+	c.pause.mu.Unlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-c.ShuttingDownChan():
+		return ErrShuttingDown
+	}
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPause(t *testing.T) {
+	for name, opts := range map[string][]Option{"": nil, " with worker pool": {WithWorkerPool(nil)}} {
+		t.Run("Limited jobs dont start while paused"+name, func(t *testing.T) {
+			c, _ := NewController(opts...)
+			count := atomic.Int32{}
+			job := RunnerFunc(func(rc *Controller) error {
+				count.Add(1)
+				return nil
+			})
+			c.Pause()
+			if !c.IsPaused() {
+				t.Errorf("expected to be paused")
+			}
+			for range 5 {
+				c.LimitedGo(job)
+			}
+			time.Sleep(5 * time.Millisecond)
+			if count.Load() != 0 {
+				t.Errorf("expected no jobs to run while paused, got %v", count.Load())
+			}
+
+			c.Resume()
+			if c.IsPaused() {
+				t.Errorf("expected to not be paused")
+			}
+			if err := c.Wait(); err != nil {
+				t.Errorf("expected no errors, got %v", err)
+			}
+			if count.Load() != 5 {
+				t.Errorf("expected 5 jobs to run after resuming, got %v", count.Load())
+			}
+		})
+	}
+	t.Run("Running jobs can wait at safe points", func(t *testing.T) {
+		c, _ := NewController()
+		steps := atomic.Int32{}
+		started := make(chan struct{})
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			close(started)
+			for range 3 {
+				select {
+				case <-rc.PausedChan():
+					if err := rc.WaitResumed(); err != nil {
+						return err
+					}
+				default:
+				}
+				steps.Add(1)
+				time.Sleep(time.Millisecond)
+			}
+			return nil
+		}))
+		<-started
+		c.Pause()
+		time.Sleep(5 * time.Millisecond)
+		if steps.Load() > 1 {
+			t.Errorf("expected job to wait while paused, got %v steps", steps.Load())
+		}
+		c.Resume()
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+		if steps.Load() != 3 {
+			t.Errorf("expected 3 steps, got %v", steps.Load())
+		}
+	})
+	t.Run("Queued jobs are skipped if shutting down while paused", func(t *testing.T) {
+		c, _ := NewController()
+		c.Pause()
+		c.LimitedGo(newRunner(nil))
+		go c.BlLimitedGo(newRunner(nil))
+		time.Sleep(time.Millisecond)
+		c.Shutdown()
+		c.Wait()
+		if c.Skipped() != 2 {
+			t.Errorf("expected 2 skipped, got %v", c.Skipped())
+		}
+	})
+}
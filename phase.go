@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"strings"
+	"sync"
+)
+
+// Phase is a group of jobs ran on a controller that can be waited on without
+// shutting down the controller (or its `Background` jobs). Errors from the
+// jobs are kept by the phase instead of the controller
+type Phase struct {
+	c       *Controller
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	errors  []string
+	skipped bool
+}
+
+// Phase returns a new Phase that runs its jobs on the controller
+func (c *Controller) Phase() *Phase {
+	return &Phase{c: c, errors: make([]string, 0)}
+}
+
+// Go same as `Controller.Go` but part of the phase
+func (p *Phase) Go(runner Runner, opts ...JobOption) {
+	p.wg.Add(1)
+	p.c.Go(runner, p.options(opts)...)
+}
+
+// BGo same as `Controller.BGo` but part of the phase
+func (p *Phase) BGo(runner Runner, opts ...JobOption) {
+	p.wg.Add(1)
+	p.c.BGo(runner, p.options(opts)...)
+}
+
+// LimitedGo same as `Controller.LimitedGo` but part of the phase
+func (p *Phase) LimitedGo(runner Runner, opts ...JobOption) {
+	p.wg.Add(1)
+	p.c.LimitedGo(runner, p.options(opts)...)
+}
+
+// BlLimitedGo same as `Controller.BlLimitedGo` but part of the phase
+func (p *Phase) BlLimitedGo(runner Runner, opts ...JobOption) {
+	p.wg.Add(1)
+	p.c.BlLimitedGo(runner, p.options(opts)...)
+}
+
+func (p *Phase) options(opts []JobOption) []JobOption {
+	return append(opts[:len(opts):len(opts)], reportErrors(p.addError), whenEnded(p.ended))
+}
+
+func (p *Phase) addError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errors = append(p.errors, errorSummary(err))
+}
+
+func (p *Phase) ended(j *job) {
+	if j.skipped {
+		p.mu.Lock()
+		p.skipped = true
+		p.mu.Unlock()
+	}
+	p.wg.Done()
+}
+
+// Wait waits for every job in the phase to finish. Returns ErrErrors if any
+// returned an error, ErrShuttingDown if any were skipped because the
+// controller is shutting down. Should not be called from a job in the phase
+func (p *Phase) Wait() error {
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.errors) > 0 {
+		return ErrErrors
+	}
+	if p.skipped {
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// Errors returns the errors from the jobs in the phase
+func (p *Phase) Errors() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return strings.Join(p.errors, ", ")
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPhase(t *testing.T) {
+	t.Run("Runs phases one after another", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(2)
+		c.Background(foreverRunnner{})
+
+		extracted := atomic.Int32{}
+		extract := c.Phase()
+		for i := 0; i < 5; i++ {
+			extract.LimitedGo(RunnerFunc(func(rc *Controller) error {
+				extracted.Add(1)
+				return nil
+			}))
+		}
+		extract.Go(RunnerFunc(func(rc *Controller) error {
+			return fmt.Errorf("bad row")
+		}))
+		if err := extract.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if extract.Errors() != "bad row" {
+			t.Errorf("expected phase errors to be 'bad row', got %v", extract.Errors())
+		}
+		if extracted.Load() != 5 {
+			t.Errorf("expected 5 jobs to finish before phase wait returns, got %v", extracted.Load())
+		}
+		if c.IsShuttingDown() {
+			t.Errorf("expected controller to not be shutting down after phase")
+		}
+
+		load := c.Phase()
+		load.BlLimitedGo(RunnerFunc(func(rc *Controller) error {
+			if extracted.Load() != 5 {
+				return fmt.Errorf("expected extract to be done")
+			}
+			return nil
+		}))
+		load.BGo(newRunner(nil))
+		if err := load.Wait(); err != nil {
+			t.Errorf("expected no error, got %v (%v)", err, load.Errors())
+		}
+
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected phase errors to not be in controller, got %v", c.Errors())
+		}
+	})
+	t.Run("Returns ErrShuttingDown if jobs are skipped", func(t *testing.T) {
+		c, _ := NewController()
+		c.Shutdown()
+		p := c.Phase()
+		p.Go(newRunner(nil))
+		p.LimitedGo(newRunner(nil))
+		if err := p.Wait(); err != ErrShuttingDown {
+			t.Errorf("expected ErrShuttingDown, got %v", err)
+		}
+		c.Wait()
+	})
+}
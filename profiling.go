@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"runtime/pprof"
+	"sync"
+)
+
+var ErrExpvarExists = fmt.Errorf("expvar already published")
+var ErrExpvarName = fmt.Errorf("expvar name is required")
+
+// expvarMu is so checking and publishing an expvar cant race (Publish panics
+// if the name is taken)
+var expvarMu sync.Mutex
+
+// WithName names the controller, used for its pprof labels and `PublishExpvar`
+func WithName(name string) Option {
+	return func(c *Controller) error {
+		c.name = name
+		return nil
+	}
+}
+
+// Name returns the name of the controller set with `WithName`
+func (c *Controller) Name() string {
+	return c.name
+}
+
+// PublishExpvar publishes `Stats` under name with expvar (so they are at
+// /debug/vars). If name is "" the controller name is used, ErrExpvarName is
+// returned if it isnt named either. Returns ErrExpvarExists if something is
+// already published under name
+func (c *Controller) PublishExpvar(name string) error {
+	if name == "" {
+		name = c.name
+	}
+	if name == "" {
+		return ErrExpvarName
+	}
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("%w: %v", ErrExpvarExists, name)
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		return c.Stats()
+	}))
+	return nil
+}
+
+// labels returns the pprof labels for the controller and j (can be nil)
+func (c *Controller) labels(j *job) pprof.LabelSet {
+	labels := make([]string, 0, 6)
+	if c.name != "" {
+		labels = append(labels, "controller", c.name)
+	}
+	if j != nil {
+		labels = append(labels, "entry", j.entry)
+		if j.name != "" {
+			labels = append(labels, "job", j.name)
+		}
+	}
+	return pprof.Labels(labels...)
+}
+
+// withLabels runs fn with the pprof labels for j, it should be called at the
+// start of a new go routine. Go routines started in fn keep the labels, so
+// profiles show which jobs go routines (i.e. waiting for the limiter) belong to
+func (c *Controller) withLabels(j *job, fn func()) {
+	pprof.Do(context.Background(), c.labels(j), func(context.Context) {
+		fn()
+	})
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"expvar"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProfiling(t *testing.T) {
+	t.Run("Jobs run with pprof labels", func(t *testing.T) {
+		for name, opts := range map[string][]Option{"": nil, " with worker pool": {WithWorkerPool(nil)}} {
+			c, _ := NewController(append(opts, WithName("labeled"))...)
+			started := make(chan struct{})
+			release := make(chan struct{})
+			c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+				close(started)
+				<-release
+				return nil
+			}), Name("blocked"))
+			<-started
+
+			buf := &bytes.Buffer{}
+			pprof.Lookup("goroutine").WriteTo(buf, 1)
+			for _, label := range []string{`"controller":"labeled"`, `"entry":"LimitedGo"`, `"job":"blocked"`} {
+				if !strings.Contains(buf.String(), label) {
+					t.Errorf("expected goroutine label %v%v", label, name)
+				}
+			}
+			close(release)
+			c.Wait()
+		}
+	})
+	t.Run("Publishes stats with expvar", func(t *testing.T) {
+		name := fmt.Sprintf("runner_expvar_test_%v", time.Now().UnixNano()) // unique if ran more than once
+		c, _ := NewController(WithName(name))
+		c.Go(newRunner(nil))
+		c.Wait()
+		if err := c.PublishExpvar(""); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		v := expvar.Get(name)
+		if v == nil || !strings.Contains(v.String(), `"Submitted":1`) {
+			t.Errorf("expected stats to be published, got %v", v)
+		}
+		if err := c.PublishExpvar(name); !errors.Is(err, ErrExpvarExists) {
+			t.Errorf("expected ErrExpvarExists, got %v", err)
+		}
+	})
+	t.Run("Expvar needs a name and can be published concurrently", func(t *testing.T) {
+		c, _ := NewController()
+		if err := c.PublishExpvar(""); err != ErrExpvarName {
+			t.Errorf("expected ErrExpvarName, got %v", err)
+		}
+
+		name := fmt.Sprintf("runner_expvar_race_test_%v", time.Now().UnixNano())
+		published := atomic.Int32{}
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if c.PublishExpvar(name) == nil {
+					published.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		if published.Load() != 1 {
+			t.Errorf("expected 1 publish to succeed, got %v", published.Load())
+		}
+		c.Wait()
+	})
+	t.Run("Children inherit the name", func(t *testing.T) {
+		c, _ := NewController(WithName("parent"))
+		child, _ := c.Child()
+		other, _ := c.Child(WithName("other"))
+		if child.Name() != "parent" || other.Name() != "other" {
+			t.Errorf("expected parent and other, got %v and %v", child.Name(), other.Name())
+		}
+		child.Wait()
+		other.Wait()
+		c.Wait()
+	})
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrInvalidRate = fmt.Errorf("rate and burst must be greater than 0")
+
+// tokenBucket allows `rate` jobs to start per second with bursts of up to `burst`
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) (*tokenBucket, error) {
+	if rate <= 0 || burst < 1 {
+		return nil, ErrInvalidRate
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// reserve takes a token and returns how long to wait before it can be used.
+// The tokens can go negative so waiters are served in the order they reserved
+func (tb *tokenBucket) reserve() time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	now := time.Now()
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+	tb.tokens -= 1
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+// cancel gives back a token that was reserved but not used
+func (tb *tokenBucket) cancel() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.tokens += 1
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+}
+
+// wait waits until a token is available. If done is closed before, it returns false
+func (tb *tokenBucket) wait(done <-chan struct{}) bool {
+	delay := tb.reserve()
+	if delay == 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-done:
+		tb.cancel()
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// WithRateLimit limits how many jobs can start per second, bursts of up to
+// burst jobs can start at once
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *Controller) error {
+		tb, err := newTokenBucket(perSecond, burst)
+		if err != nil {
+			return err
+		}
+		c.rateLimit = tb
+		return nil
+	}
+}
+
+// WithKeyedRateLimit adds a rate limiter that only jobs submitted with
+// `RateKey(key)` will wait for
+func WithKeyedRateLimit(key string, perSecond float64, burst int) Option {
+	return func(c *Controller) error {
+		tb, err := newTokenBucket(perSecond, burst)
+		if err != nil {
+			return err
+		}
+		if c.keyedRateLimits == nil {
+			c.keyedRateLimits = make(map[string]*tokenBucket)
+		}
+		c.keyedRateLimits[key] = tb
+		return nil
+	}
+}
+
+// waitForRate waits for the global rate limiter and the one for key. If
+// shutdown before, it returns false
+func (c *Controller) waitForRate(key string) bool {
+	if c.rateLimit != nil && !c.rateLimit.wait(c.doneChan) {
+		return false
+	}
+	if key == "" {
+		return true
+	}
+	tb, ok := c.keyedRateLimits[key]
+	if !ok {
+		log.Warnf("No rate limit for key %v", key)
+		return true
+	}
+	if !tb.wait(c.doneChan) {
+		if c.rateLimit != nil {
+			c.rateLimit.cancel() // the global token wont be used either
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("returns error if bad rate", func(t *testing.T) {
+		if _, err := NewController(WithRateLimit(0, 1)); err != ErrInvalidRate {
+			t.Errorf("expected invalid rate error, got %v", err)
+		}
+		if _, err := NewController(WithKeyedRateLimit("foo", 1, 0)); err != ErrInvalidRate {
+			t.Errorf("expected invalid rate error, got %v", err)
+		}
+	})
+	t.Run("Limits how fast jobs start", func(t *testing.T) {
+		c, _ := NewController(WithRateLimit(100, 2))
+		count := atomic.Int32{}
+		job := RunnerFunc(func(rc *Controller) error {
+			count.Add(1)
+			return nil
+		})
+
+		start := time.Now()
+		for i := 0; i < 6; i++ {
+			if i%2 == 0 {
+				c.Go(job)
+			} else {
+				c.LimitedGo(job)
+			}
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+		// 2 can burst, the other 4 have to wait 10ms each
+		if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+			t.Errorf("expected jobs to be rate limited, took %v", elapsed)
+		}
+		if count.Load() != 6 {
+			t.Errorf("expected 6 jobs to run, got %v", count.Load())
+		}
+	})
+	t.Run("Only keyed jobs wait for keyed limit", func(t *testing.T) {
+		c, _ := NewController(WithKeyedRateLimit("slow", 1, 1))
+		keyed := atomic.Int32{}
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			keyed.Add(1)
+			return nil
+		}), RateKey("slow"))
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			keyed.Add(1)
+			return nil
+		}), RateKey("slow"))
+
+		other := make(chan struct{})
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			close(other)
+			return nil
+		}))
+		select {
+		case <-other:
+		case <-time.After(500 * time.Millisecond):
+			t.Errorf("expected job without key to not be rate limited")
+		}
+		c.Shutdown()
+		c.Wait()
+		if keyed.Load() != 1 {
+			t.Errorf("expected second keyed job to be skipped, got %v ran", keyed.Load())
+		}
+	})
+	t.Run("Limited jobs wait for a token before taking a slot", func(t *testing.T) {
+		for _, opts := range [][]Option{{WithRateLimit(1, 1)}, {WithRateLimit(1, 1), WithWorkerPool(nil)}} {
+			c, _ := NewControllerWithLimit(2, opts...)
+			c.LimitedGo(RunnerFunc(func(rc *Controller) error { return nil }))
+			c.LimitedGo(RunnerFunc(func(rc *Controller) error { return nil })) // waits 1s for a token
+			time.Sleep(20 * time.Millisecond)
+			if stats := c.Stats(); stats.InUse != 0 || stats.Waiting != 0 {
+				t.Errorf("expected jobs waiting for a token to not use the limiter, got %v in use and %v waiting", stats.InUse, stats.Waiting)
+			}
+			c.Shutdown()
+			c.Wait()
+		}
+	})
+	t.Run("Gives back the global token if the keyed wait fails", func(t *testing.T) {
+		c, _ := NewController(WithRateLimit(1, 2), WithKeyedRateLimit("slow", 1, 1))
+		c.rateLimit.reserve() // so the keyed job takes the last global token
+		c.keyedRateLimits["slow"].reserve()
+		c.Go(RunnerFunc(func(rc *Controller) error { return nil }), RateKey("slow"))
+		time.Sleep(10 * time.Millisecond)
+		c.Shutdown()
+		c.Wait()
+		if delay := c.rateLimit.reserve(); delay != 0 {
+			t.Errorf("expected the global token to be given back, would wait %v", delay)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrNotReady = fmt.Errorf("exited before ready")
+var ErrReadyTimeout = fmt.Errorf("timed out waiting for ready")
+
+// WithReadyTimeout is how long `WaitReady` (and jobs using `Requires`) wait
+// for a background job to be ready. If it isnt by then, the controller is
+// shutdown with ErrReadyTimeout. Defaults to waiting forever
+func WithReadyTimeout(timeout time.Duration) Option {
+	return func(c *Controller) error {
+		c.readyTimeout = timeout
+		return nil
+	}
+}
+
+// readiness is the state of a named background job
+type readiness struct {
+	ready  chan struct{} // closed by `Ready`
+	exited chan struct{} // closed when the job exits
+}
+
+type readinessRegistry struct {
+	mu    sync.Mutex
+	names map[string]*readiness
+}
+
+func newReadinessRegistry() *readinessRegistry {
+	return &readinessRegistry{names: make(map[string]*readiness)}
+}
+
+// get returns the readiness for name, creating it if it doesnt exist yet so
+// things can wait on jobs that havent started
+func (r *readinessRegistry) get(name string) *readiness {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rd, ok := r.names[name]
+	if !ok {
+		rd = &readiness{ready: make(chan struct{}), exited: make(chan struct{})}
+		r.names[name] = rd
+	}
+	return rd
+}
+
+// Ready is called from inside a job (started with the `Name` option) to let
+// everything waiting on it (`WaitReady` or `Requires`) know it is ready
+func (c *Controller) Ready() {
+	if c.job == nil || c.job.name == "" {
+		log.Warn("Ready called without a named job")
+		return
+	}
+	rd := c.readiness.get(c.job.name)
+	c.readiness.mu.Lock()
+	defer c.readiness.mu.Unlock()
+	select {
+	case <-rd.ready:
+	default:
+		log.Debugf("%v is ready", c.job.name)
+		close(rd.ready)
+	}
+}
+
+// notReady is called when j exits so anything still waiting on it stops
+func (c *Controller) notReady(j *job) {
+	if j.name == "" {
+		return
+	}
+	rd := c.readiness.get(j.name)
+	c.readiness.mu.Lock()
+	defer c.readiness.mu.Unlock()
+	select {
+	case <-rd.exited:
+	default:
+		close(rd.exited)
+	}
+}
+
+// WaitReady waits until the job named name calls `Ready`. Returns
+// ErrShuttingDown if shutting down before, ErrNotReady if the job exited
+// without being ready, or ErrReadyTimeout if it took longer than
+// `WithReadyTimeout` (which also shuts down the controller)
+func (c *Controller) WaitReady(name string) error {
+	rd := c.readiness.get(name)
+	var timeout <-chan time.Time
+	if c.readyTimeout > 0 {
+		timer := time.NewTimer(c.readyTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-rd.ready:
+		return nil
+	default:
+	}
+	select {
+	case <-rd.ready:
+		return nil
+	case <-rd.exited:
+		return fmt.Errorf("%w: %v", ErrNotReady, name)
+	case <-c.doneChan:
+		return ErrShuttingDown
+	case <-timeout:
+		err := fmt.Errorf("%w: %v", ErrReadyTimeout, name)
+		c.fail(err)
+		return err
+	}
+}
+
+// waitForRequired waits for everything j requires to be ready. Returns false
+// if one wont be
+func (c *Controller) waitForRequired(j *job) bool {
+	for _, name := range j.requires {
+		if err := c.WaitReady(name); err != nil {
+			log.Debugf("Not running %v because %v", j, err)
+			return false
+		}
+	}
+	return true
+}
+
+// fail adds the error and shuts down the controller, it can be called from
+// outside of a job
+func (c *Controller) fail(err error) {
+	c.addCount(c.mainCountChan, nil, func(finished func()) {
+		defer finished()
+		c.addError(err)
+	})
+	c.Shutdown()
+}
@@ -0,0 +1,142 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// serverRunner becomes ready after a delay and runs until shutdown
+type serverRunner struct {
+	delay time.Duration
+	ready atomic.Bool
+}
+
+func (s *serverRunner) Run(rc *Controller) error {
+	select {
+	case <-time.After(s.delay):
+	case <-rc.ShuttingDownChan():
+		return nil
+	}
+	s.ready.Store(true)
+	rc.Ready()
+	<-rc.ShuttingDownChan()
+	return nil
+}
+
+func TestReady(t *testing.T) {
+	t.Run("Jobs wait for required background jobs", func(t *testing.T) {
+		c, _ := NewController()
+		db := &serverRunner{delay: 5 * time.Millisecond}
+		cache := &serverRunner{delay: time.Millisecond}
+		c.Background(db, Name("db"))
+		c.Background(cache, Name("cache"))
+
+		job := RunnerFunc(func(rc *Controller) error {
+			if !db.ready.Load() || !cache.ready.Load() {
+				return fmt.Errorf("started before ready")
+			}
+			return nil
+		})
+		c.Go(job, Requires("db", "cache"))
+		c.LimitedGo(job, Requires("db"), Requires("cache"))
+
+		if err := c.WaitReady("db"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+	})
+	t.Run("Can wait before the background job is started", func(t *testing.T) {
+		c, _ := NewController()
+		waited := make(chan error)
+		go func() {
+			waited <- c.WaitReady("db")
+		}()
+		c.Background(&serverRunner{}, Name("db"))
+		if err := <-waited; err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		c.Wait()
+	})
+	t.Run("Returns ErrNotReady if exited before ready", func(t *testing.T) {
+		c, _ := NewController()
+		c.Background(newRunner(nil), Name("db"))
+		if err := c.WaitReady("db"); !errors.Is(err, ErrNotReady) {
+			t.Errorf("expected ErrNotReady, got %v", err)
+		}
+		c.Wait()
+	})
+	t.Run("Fails the controller after the ready timeout", func(t *testing.T) {
+		c, _ := NewController(WithReadyTimeout(5 * time.Millisecond))
+		c.Background(&serverRunner{delay: time.Second}, Name("db"))
+		ran := false
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			ran = true
+			return nil
+		}), Requires("db"))
+
+		if err := c.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if c.Errors() != "timed out waiting for ready: db" {
+			t.Errorf("expected timeout error, got %v", c.Errors())
+		}
+		if ran {
+			t.Errorf("expected job to be skipped")
+		}
+	})
+	t.Run("Limited jobs dont hold a slot while waiting", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(1)
+		db := &serverRunner{delay: time.Second}
+		c.Background(db, Name("db"))
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error { return nil }), Requires("db"))
+		ran := make(chan struct{})
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			close(ran)
+			return nil
+		}))
+		select {
+		case <-ran:
+		case <-time.After(500 * time.Millisecond):
+			t.Errorf("expected job to not wait for the one waiting on db")
+		}
+		c.Shutdown()
+		c.Wait()
+	})
+	t.Run("Background jobs wait for what they require", func(t *testing.T) {
+		c, _ := NewController()
+		db := &serverRunner{delay: 5 * time.Millisecond}
+		c.Background(db, Name("db"))
+		started := make(chan bool, 1)
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			started <- db.ready.Load()
+			<-rc.ShuttingDownChan()
+			return nil
+		}), Requires("db"))
+		if !<-started {
+			t.Errorf("expected background job to start after db was ready")
+		}
+		c.Shutdown()
+		c.Wait()
+
+		c, _ = NewController()
+		c.Background(newRunner(nil), Name("db"))
+		ran := false
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			ran = true
+			return nil
+		}), Requires("db"))
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			rc.WaitReady("db")
+			return nil
+		}))
+		c.Wait()
+		if ran {
+			t.Errorf("expected background job to be skipped")
+		}
+	})
+}
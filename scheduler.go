@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var ErrInvalidInterval = fmt.Errorf("interval must be greater than 0")
+
+// ScheduleConfig configures a scheduled job
+type ScheduleConfig struct {
+	// Jitter adds a random delay between 0 and Jitter to every run
+	Jitter time.Duration
+	// NoOverlap skips a run if the previous one is still running
+	NoOverlap bool
+	// Location is the time zone the schedule is in, defaults to time.Local
+	Location *time.Location
+}
+
+// Every runs r every interval in the background until shutting down. Returns
+// ErrInvalidInterval if interval isnt greater than 0
+func (c *Controller) Every(interval time.Duration, r Runner, config ScheduleConfig) error {
+	return c.Schedule(Every(interval), r, config)
+}
+
+// Cron runs r in the background on the cron expression (see `ParseCron`)
+// until shutting down
+func (c *Controller) Cron(expr string, r Runner, config ScheduleConfig) error {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		return err
+	}
+	return c.Schedule(schedule, r, config)
+}
+
+// Schedule runs r in the background every time the schedule says to until
+// shutting down. Errors from r are added to the controller but dont stop the
+// schedule. It is started with `Background` so it doesnt keep the controller
+// from finishing. Returns ErrInvalidInterval for an `Every` schedule that
+// isnt greater than 0
+func (c *Controller) Schedule(schedule Schedule, r Runner, config ScheduleConfig) error {
+	if every, ok := schedule.(everySchedule); ok && every <= 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidInterval, time.Duration(every))
+	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+	c.Background(&scheduled{schedule: schedule, runner: r, config: config}, internalRunner())
+	return nil
+}
+
+type scheduled struct {
+	schedule Schedule
+	runner   Runner
+	config   ScheduleConfig
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	running  bool
+}
+
+func (s *scheduled) Run(rc *Controller) error {
+	defer s.wg.Wait()
+	last := time.Now().In(s.config.Location)
+	for {
+		next := s.schedule.Next(last)
+		if next.IsZero() {
+			log.Warn("Schedule has no next run")
+			return nil
+		}
+		if !next.After(last) {
+			log.Warnf("Schedule next run %v isnt after %v", next, last)
+			return nil
+		}
+		last = next
+		if s.config.Jitter > 0 {
+			next = next.Add(time.Duration(rand.Int64N(int64(s.config.Jitter))))
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-rc.ShuttingDownChan():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		if !s.start() {
+			log.Debug("Skipping scheduled run, previous still running")
+			continue
+		}
+		s.wg.Add(1)
+		j := rc.newJob("Schedule", s.runner, nil)
+		go rc.withLabels(j, func() {
+			defer s.wg.Done()
+			defer s.finish()
+			rc.jobs.add(j)
+			rc.runJob(j, nil)
+			rc.endJob(j)
+		})
+		if now := time.Now().In(s.config.Location); now.After(last) {
+			last = now // dont try to catch up on missed runs
+		}
+	}
+}
+
+// start returns false if the run should be skipped because of NoOverlap
+func (s *scheduled) start() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config.NoOverlap && s.running {
+		return false
+	}
+	s.running = true
+	return true
+}
+
+func (s *scheduled) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+}
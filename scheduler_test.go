@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stuckSchedule never moves forward
+type stuckSchedule struct{}
+
+func (stuckSchedule) Next(after time.Time) time.Time { return after }
+
+func TestScheduler(t *testing.T) {
+	t.Run("Runs every interval until shutting down", func(t *testing.T) {
+		c, _ := NewController()
+		count := atomic.Int32{}
+		c.Every(2*time.Millisecond, RunnerFunc(func(rc *Controller) error {
+			if count.Add(1) == 2 {
+				return fmt.Errorf("bad run")
+			}
+			return nil
+		}), ScheduleConfig{Jitter: time.Millisecond})
+
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			for count.Load() < 4 {
+				time.Sleep(time.Millisecond)
+			}
+			return nil
+		}))
+		if err := c.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if c.Errors() != "bad run" {
+			t.Errorf("expected errors to be 'bad run', got %v", c.Errors())
+		}
+	})
+	t.Run("No overlap skips runs", func(t *testing.T) {
+		c, _ := NewController()
+		running := atomic.Int32{}
+		count := atomic.Int32{}
+		c.Every(time.Millisecond, RunnerFunc(func(rc *Controller) error {
+			defer running.Add(-1)
+			if running.Add(1) > 1 {
+				return fmt.Errorf("overlapped")
+			}
+			count.Add(1)
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}), ScheduleConfig{NoOverlap: true})
+
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			for count.Load() < 3 {
+				time.Sleep(time.Millisecond)
+			}
+			return nil
+		}))
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+	})
+	t.Run("Cron returns error for bad expression", func(t *testing.T) {
+		c, _ := NewController()
+		if err := c.Cron("bad", newRunner(nil), ScheduleConfig{}); err == nil {
+			t.Errorf("expected error")
+		}
+		if err := c.Cron("* * * * * *", newRunner(nil), ScheduleConfig{Location: time.UTC}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		c.Wait()
+	})
+	t.Run("Rejects intervals that arent greater than 0", func(t *testing.T) {
+		c, _ := NewController()
+		runs := atomic.Int32{}
+		job := RunnerFunc(func(rc *Controller) error {
+			runs.Add(1)
+			return nil
+		})
+		if err := c.Every(0, job, ScheduleConfig{}); !errors.Is(err, ErrInvalidInterval) {
+			t.Errorf("expected ErrInvalidInterval, got %v", err)
+		}
+		if err := c.Schedule(Every(-time.Second), job, ScheduleConfig{}); !errors.Is(err, ErrInvalidInterval) {
+			t.Errorf("expected ErrInvalidInterval, got %v", err)
+		}
+		c.Schedule(stuckSchedule{}, job, ScheduleConfig{})
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}))
+		c.Wait()
+		if runs.Load() != 0 {
+			t.Errorf("expected nothing to run, got %v", runs.Load())
+		}
+	})
+}
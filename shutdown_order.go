@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"slices"
+	"sync"
+)
+
+// WithOrderedShutdown stops `Background` jobs one at a time when shutting
+// down instead of all at once. They are stopped in reverse order they were
+// started (unless `ShutdownPriority` is used), each one after the one before
+// it exited. i.e. a metrics flusher started first outlives the workers that
+// feed it. Each job sees its own `ShuttingDownChan`
+func WithOrderedShutdown() Option {
+	return func(c *Controller) error {
+		c.backgrounds = &backgroundRegistry{}
+		return nil
+	}
+}
+
+// ShutdownPriority is used with `WithOrderedShutdown`, background jobs with a
+// lower priority are stopped first (defaults to 0). Jobs with the same
+// priority are stopped in reverse order they were started
+func ShutdownPriority(priority int) JobOption {
+	return func(j *job) {
+		j.priority = priority
+	}
+}
+
+type backgroundRegistry struct {
+	mu       sync.Mutex
+	jobs     []*job
+	stopping bool
+}
+
+// registerBackground gives j its own stop channel if using ordered shutdown
+func (c *Controller) registerBackground(j *job) {
+	if c.backgrounds == nil {
+		return
+	}
+	j.stop = make(chan struct{})
+	j.exited = make(chan struct{})
+
+	c.backgrounds.mu.Lock()
+	defer c.backgrounds.mu.Unlock()
+	if c.backgrounds.stopping {
+		close(j.stop) // started while already stopping the others
+		return
+	}
+	c.backgrounds.jobs = append(c.backgrounds.jobs, j)
+}
+
+// stopBackgrounds waits for shutdown then stops the background jobs in order
+func (c *Controller) stopBackgrounds() {
+	<-c.doneChan
+
+	c.backgrounds.mu.Lock()
+	c.backgrounds.stopping = true
+	jobs := slices.Clone(c.backgrounds.jobs)
+	c.backgrounds.mu.Unlock()
+
+	slices.Reverse(jobs)
+	slices.SortStableFunc(jobs, func(a, b *job) int {
+		return a.priority - b.priority
+	})
+	for _, j := range jobs {
+		log.Debugf("Stopping background job %v", j.name)
+		close(j.stop)
+		select {
+		case <-j.exited:
+		case <-c.finishChan:
+			return
+		}
+	}
+}
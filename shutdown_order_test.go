@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stopOrder records the order background jobs stop in
+type stopOrder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (s *stopOrder) runner(name string) Runner {
+	return RunnerFunc(func(rc *Controller) error {
+		<-rc.ShuttingDownChan()
+		time.Sleep(time.Millisecond) // so the next one would be first if not waited for
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.names = append(s.names, name)
+		return nil
+	})
+}
+
+func TestOrderedShutdown(t *testing.T) {
+	t.Run("Stops in reverse order", func(t *testing.T) {
+		c, _ := NewController(WithOrderedShutdown())
+		order := &stopOrder{}
+		c.Background(order.runner("flusher"))
+		c.Background(order.runner("worker1"))
+		c.Background(order.runner("worker2"))
+		c.Go(newRunner(nil))
+
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+		expected := []string{"worker2", "worker1", "flusher"}
+		if !slices.Equal(order.names, expected) {
+			t.Errorf("expected %v, got %v", expected, order.names)
+		}
+	})
+	t.Run("Stops by priority", func(t *testing.T) {
+		c, _ := NewController(WithOrderedShutdown())
+		order := &stopOrder{}
+		c.Background(order.runner("worker1"))
+		c.Background(order.runner("flusher"), ShutdownPriority(1))
+		c.Background(order.runner("worker2"))
+		c.Background(order.runner("first"), ShutdownPriority(-1))
+		c.Shutdown()
+		c.Wait()
+
+		expected := []string{"first", "worker2", "worker1", "flusher"}
+		if !slices.Equal(order.names, expected) {
+			t.Errorf("expected %v, got %v", expected, order.names)
+		}
+	})
+	t.Run("Earlier jobs keep running while later ones stop", func(t *testing.T) {
+		c, _ := NewController(WithOrderedShutdown())
+		flusher := make(chan *Controller, 1)
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			flusher <- rc
+			<-rc.ShuttingDownChan()
+			return nil
+		}))
+		flusherRc := <-flusher
+		stopped := make(chan bool, 1)
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			<-rc.ShuttingDownChan()
+			stopped <- flusherRc.IsShuttingDown()
+			return nil
+		}))
+		c.Shutdown()
+		c.Wait()
+		if <-stopped {
+			t.Errorf("expected the earlier job to not be stopped yet")
+		}
+		if !c.IsShuttingDown() {
+			t.Errorf("expected the controller to be shutting down")
+		}
+	})
+	t.Run("Without the option all stop at once", func(t *testing.T) {
+		c, _ := NewController()
+		stopped := make(chan struct{})
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			<-rc.ShuttingDownChan()
+			<-stopped // would deadlock if waiting for the later one
+			return nil
+		}))
+		c.Background(RunnerFunc(func(rc *Controller) error {
+			<-rc.ShuttingDownChan()
+			close(stopped)
+			return nil
+		}))
+		c.Shutdown()
+		c.Wait()
+	})
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+)
+
+// flights tracks the keys used by `GoOnce` and `Do`
+type flights struct {
+	mu    sync.Mutex
+	once  map[string]struct{} // keys already submitted with `GoOnce`
+	calls map[string]*flight  // `Do` calls in flight
+}
+
+type flight struct {
+	done chan struct{} // closed once val and err are set
+	val  any
+	err  error
+	dups int // other callers waiting on the result
+}
+
+func newFlights() *flights {
+	return &flights{
+		once:  make(map[string]struct{}),
+		calls: make(map[string]*flight),
+	}
+}
+
+// GoOnce is the same as `Go` but only the first job submitted with key is ran
+// for the lifetime of the controller (unless `Forget` is called). Returns
+// true if the runner was submitted, false if one was already for key or it
+// was skipped because shutting down (then key isnt used up)
+func (c *Controller) GoOnce(key string, runner Runner, opts ...JobOption) bool {
+	c.flights.mu.Lock()
+	if _, ok := c.flights.once[key]; ok {
+		c.flights.mu.Unlock()
+		log.Debugf("Already ran job for %v", key)
+		return false
+	}
+	c.flights.once[key] = struct{}{} // reserved so jobs submitted at the same time dont run too
+	c.flights.mu.Unlock()
+
+	if !c.goJob(c.newJob("GoOnce", runner, opts)) {
+		c.flights.mu.Lock()
+		delete(c.flights.once, key)
+		c.flights.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// Forget lets key be used again by `GoOnce`, and makes the next `Do` with key
+// run fn even if one is still in flight
+func (c *Controller) Forget(key string) {
+	c.flights.mu.Lock()
+	defer c.flights.mu.Unlock()
+	delete(c.flights.once, key)
+	delete(c.flights.calls, key)
+}
+
+// Do runs fn in the current thread (same as `BGo`) and returns the result. If
+// `Do` is called with the same key while fn is still running, fn is not ran
+// again and instead every caller gets the same result and error. Returns
+// ErrShuttingDown if the controller is shutting down (or draining) before fn
+// is ran. If fn panics it is panicked again and the callers waiting get
+// ErrPanic
+func Do[T any](c *Controller, key string, fn func(rc *Controller) (T, error)) (T, error) {
+	c.flights.mu.Lock()
+	if f, ok := c.flights.calls[key]; ok {
+		f.dups++
+		c.flights.mu.Unlock()
+		<-f.done
+		val, _ := f.val.(T)
+		return val, f.err
+	}
+	f := &flight{done: make(chan struct{}), err: ErrShuttingDown}
+	c.flights.calls[key] = f
+	c.flights.mu.Unlock()
+
+	defer func() {
+		// the callers waiting get ErrPanic and it is panicked again here
+		r := recover()
+		if r != nil {
+			f.val, f.err = nil, fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+		c.flights.land(key, f)
+		if r != nil {
+			panic(r)
+		}
+	}()
+	if c.IsDraining() {
+		log.Debugf("Not running Do for %v b/c draining", key)
+	} else {
+		c.addCount(c.mainCountChan, nil, func(finished func()) {
+			defer finished()
+			f.val, f.err = fn(c.scoped(nil, nil))
+		})
+	}
+
+	val, _ := f.val.(T)
+	return val, f.err
+}
+
+// land removes f from the calls in flight and lets the callers waiting on it
+// have the result
+func (fl *flights) land(key string, f *flight) {
+	fl.mu.Lock()
+	if fl.calls[key] == f {
+		delete(fl.calls, key)
+	}
+	fl.mu.Unlock()
+	close(f.done)
+}
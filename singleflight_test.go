@@ -0,0 +1,172 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func (c *Controller) flightWaiters(key string) int {
+	c.flights.mu.Lock()
+	defer c.flights.mu.Unlock()
+	if f, ok := c.flights.calls[key]; ok {
+		return f.dups
+	}
+	return 0
+}
+
+func TestSingleflight(t *testing.T) {
+	t.Run("GoOnce only runs the first job for a key", func(t *testing.T) {
+		c, _ := NewController()
+		count := atomic.Int32{}
+		fetch := RunnerFunc(func(rc *Controller) error {
+			count.Add(1)
+			return nil
+		})
+		for i := 0; i < 10; i++ {
+			c.Go(RunnerFunc(func(rc *Controller) error {
+				rc.GoOnce("https://example.com", fetch)
+				return nil
+			}))
+		}
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v", err)
+		}
+		if count.Load() != 1 {
+			t.Errorf("expected 1 fetch, got %v", count.Load())
+		}
+		if submitted := c.Stats().Entries["GoOnce"].Submitted; submitted != 1 {
+			t.Errorf("expected 1 GoOnce job, got %v", submitted)
+		}
+	})
+	t.Run("GoOnce can be used again after Forget", func(t *testing.T) {
+		c, _ := NewController()
+		if !c.GoOnce("foo", newRunner(nil)) {
+			t.Errorf("expected first GoOnce to submit")
+		}
+		if c.GoOnce("foo", newRunner(nil)) {
+			t.Errorf("expected second GoOnce to not submit")
+		}
+		c.Forget("foo")
+		if !c.GoOnce("foo", newRunner(nil)) {
+			t.Errorf("expected GoOnce after Forget to submit")
+		}
+		c.Wait()
+	})
+	t.Run("Do shares the result with callers in flight", func(t *testing.T) {
+		c, _ := NewController()
+		count := atomic.Int32{}
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		results := make([]string, 5)
+		errs := make([]error, 5)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = Do(c, "foo", func(rc *Controller) (string, error) {
+					count.Add(1)
+					close(started)
+					<-release
+					return "bar", fmt.Errorf("chew")
+				})
+			}()
+			if i == 0 {
+				<-started
+			}
+		}
+		for c.flightWaiters("foo") < 4 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		wg.Wait()
+
+		if count.Load() != 1 {
+			t.Errorf("expected fn to run once, got %v", count.Load())
+		}
+		for i := range results {
+			if results[i] != "bar" || errs[i] == nil || errs[i].Error() != "chew" {
+				t.Errorf("expected bar and chew, got %v and %v", results[i], errs[i])
+			}
+		}
+		c.Wait()
+	})
+	t.Run("Do returns ErrShuttingDown if shutting down", func(t *testing.T) {
+		c, _ := NewController()
+		c.Shutdown()
+		_, err := Do(c, "foo", func(rc *Controller) (int, error) {
+			return 1, nil
+		})
+		if err != ErrShuttingDown {
+			t.Errorf("expected ErrShuttingDown, got %v", err)
+		}
+		c.Wait()
+	})
+	t.Run("Skipped jobs dont use up the key", func(t *testing.T) {
+		c, _ := NewController()
+		release := make(chan struct{})
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			<-release
+			return nil
+		}))
+		c.Drain()
+		if c.GoOnce("foo", newRunner(nil)) {
+			t.Errorf("expected GoOnce to be skipped while draining")
+		}
+		_, err := Do(c, "foo", func(rc *Controller) (int, error) {
+			return 1, nil
+		})
+		if err != ErrShuttingDown {
+			t.Errorf("expected Do to return ErrShuttingDown while draining, got %v", err)
+		}
+		close(release)
+		c.Wait()
+
+		c.flights.mu.Lock()
+		_, used := c.flights.once["foo"]
+		c.flights.mu.Unlock()
+		if used {
+			t.Errorf("expected the key to not be used by a skipped job")
+		}
+	})
+	t.Run("Do doesnt leave the key in flight if fn panics", func(t *testing.T) {
+		c, _ := NewController()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		panicked := make(chan any)
+		go func() {
+			defer func() { panicked <- recover() }()
+			Do(c, "foo", func(rc *Controller) (int, error) {
+				close(started)
+				<-release
+				panic("oops")
+			})
+		}()
+		<-started
+
+		dupErr := make(chan error)
+		go func() {
+			_, err := Do(c, "foo", func(rc *Controller) (int, error) { return 1, nil })
+			dupErr <- err
+		}()
+		for c.flightWaiters("foo") < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		if r := <-panicked; r != "oops" {
+			t.Errorf("expected the panic to be passed on, got %v", r)
+		}
+		if err := <-dupErr; !errors.Is(err, ErrPanic) {
+			t.Errorf("expected ErrPanic for the caller waiting, got %v", err)
+		}
+		if val, err := Do(c, "foo", func(rc *Controller) (int, error) { return 2, nil }); val != 2 || err != nil {
+			t.Errorf("expected the next Do to run, got %v and %v", val, err)
+		}
+		c.Wait()
+	})
+}
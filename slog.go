@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// WithLogger makes the controller log jobs (and its counts) to logger with
+// structured attributes instead of the package logger, and is what
+// `Logger` is built from
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Controller) error {
+		c.slog = logger
+		return nil
+	}
+}
+
+// Logger returns a logger with the attributes of the job being ran (id, name,
+// entry point, parent, labels and worker). It is built from the `WithLogger`
+// logger, or slog.Default if not set
+func (c *Controller) Logger() *slog.Logger {
+	logger := c.slog
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger.With(c.logAttrs(c.job)...)
+}
+
+// logAttrs returns the attributes for the controller and j (can be nil)
+func (c *Controller) logAttrs(j *job) []any {
+	attrs := make([]any, 0, 6)
+	if c.name != "" {
+		attrs = append(attrs, slog.String("controller", c.name))
+	}
+	if j != nil {
+		attrs = append(attrs, slog.Uint64("job_id", j.id), slog.String("entry", j.entry))
+		if j.name != "" {
+			attrs = append(attrs, slog.String("job", j.name))
+		}
+		if j.parent != 0 {
+			attrs = append(attrs, slog.Uint64("parent_id", j.parent))
+		}
+		if len(j.labels) > 0 {
+			keys := make([]string, 0, len(j.labels))
+			for key := range j.labels {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			labels := make([]any, 0, len(keys))
+			for _, key := range keys {
+				labels = append(labels, slog.String(key, j.labels[key]))
+			}
+			attrs = append(attrs, slog.Group("labels", labels...))
+		}
+	}
+	if c.worker != nil {
+		attrs = append(attrs, slog.Int("worker", c.worker.index))
+	}
+	return attrs
+}
+
+// debugJob logs msg about j (can be nil) with args (key value pairs) to the
+// `WithLogger` logger if set, otherwise to the package logger
+func (c *Controller) debugJob(j *job, msg string, args ...any) {
+	if c.slog != nil {
+		if c.slog.Enabled(context.Background(), slog.LevelDebug) {
+			c.slog.With(c.logAttrs(j)...).Debug(msg, args...)
+		}
+		return
+	}
+	// formatted by the package logger so nothing is done if it is a no-op
+	format, values := "%s", []any{msg}
+	if j != nil {
+		format, values = "%v: %s", []any{j, msg}
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		format += " %v=%v"
+		values = append(values, args[i], args[i+1])
+	}
+	log.Debugf(format, values...)
+}
+
+// SetSlogLogger uses logger for the package logger (see `SetLogger`)
+func SetSlogLogger(logger *slog.Logger) {
+	SetLogger(slogLogger{logger})
+}
+
+// slogLogger lets a slog.Logger be used as the package logger
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Debug(x ...interface{})            { s.l.Debug(fmt.Sprint(x...)) }
+func (s slogLogger) Debugf(y string, x ...interface{}) { s.l.Debug(fmt.Sprintf(y, x...)) }
+func (s slogLogger) Info(x ...interface{})             { s.l.Info(fmt.Sprint(x...)) }
+func (s slogLogger) Infof(y string, x ...interface{})  { s.l.Info(fmt.Sprintf(y, x...)) }
+func (s slogLogger) Warn(x ...interface{})             { s.l.Warn(fmt.Sprint(x...)) }
+func (s slogLogger) Warnf(y string, x ...interface{})  { s.l.Warn(fmt.Sprintf(y, x...)) }
+func (s slogLogger) Error(x ...interface{})            { s.l.Error(fmt.Sprint(x...)) }
+func (s slogLogger) Errorf(y string, x ...interface{}) { s.l.Error(fmt.Sprintf(y, x...)) }
+func (s slogLogger) Fatal(x ...interface{})            { s.l.Error(fmt.Sprint(x...)) }
+func (s slogLogger) Fatalf(y string, x ...interface{}) { s.l.Error(fmt.Sprintf(y, x...)) }
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer that can be written to concurrently
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+// records returns every json log line
+func (s *syncBuffer) records(t *testing.T) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(s.buf.String()), "\n") {
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("expected json log, got %v", line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// countingStringer counts how many times it was formatted
+type countingStringer struct{ calls *atomic.Int32 }
+
+func (s countingStringer) String() string {
+	s.calls.Add(1)
+	return "value"
+}
+
+// debugLogger keeps debug logs
+type debugLogger struct {
+	emptyLogger
+	logs []string
+}
+
+func (d *debugLogger) Debugf(f string, x ...interface{}) {
+	d.logs = append(d.logs, fmt.Sprintf(f, x...))
+}
+
+func TestSlog(t *testing.T) {
+	t.Run("Logger has the job attributes", func(t *testing.T) {
+		buf := &syncBuffer{}
+		logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		c, _ := NewController(WithLogger(logger), WithName("ctrl"))
+		c.BGo(RunnerFunc(func(rc *Controller) error {
+			rc.Logger().Info("hello")
+			return nil
+		}), Name("greeter"), Label("tenant", "a"))
+		c.Wait()
+
+		found := map[string]map[string]any{}
+		for _, record := range buf.records(t) {
+			found[record["msg"].(string)] = record
+		}
+		hello := found["hello"]
+		if hello == nil {
+			t.Fatalf("expected hello log, got %v", found)
+		}
+		if hello["job"] != "greeter" || hello["entry"] != "BGo" || hello["controller"] != "ctrl" || hello["job_id"] == nil {
+			t.Errorf("expected job attributes, got %v", hello)
+		}
+		if labels, _ := hello["labels"].(map[string]any); labels["tenant"] != "a" {
+			t.Errorf("expected labels group, got %v", hello["labels"])
+		}
+		if found["Starting"]["job"] != "greeter" {
+			t.Errorf("expected controller debug logs with attributes, got %v", found["Starting"])
+		}
+		if counts := found["Counts"]; counts == nil || counts["main"] == nil {
+			t.Errorf("expected counts log, got %v", counts)
+		}
+	})
+	t.Run("Logger outside a job defaults to slog.Default", func(t *testing.T) {
+		c, _ := NewController()
+		if c.Logger() == nil {
+			t.Errorf("expected a logger")
+		}
+		c.Wait()
+	})
+	t.Run("Package logger can use slog", func(t *testing.T) {
+		buf := &syncBuffer{}
+		SetSlogLogger(slog.New(slog.NewJSONHandler(buf, nil)))
+		defer SetLogger(emptyLogger{})
+		log.Infof("hello %v", "world")
+		if records := buf.records(t); records[0]["msg"] != "hello world" {
+			t.Errorf("expected hello world, got %v", records)
+		}
+	})
+	t.Run("Package logger formats job logs", func(t *testing.T) {
+		c, _ := NewController()
+		calls := atomic.Int32{}
+		c.debugJob(nil, "Counts", "main", countingStringer{&calls})
+		if calls.Load() != 0 {
+			t.Errorf("expected nothing to be formatted for a no-op logger, got %v", calls.Load())
+		}
+		c.Wait()
+
+		logger := &debugLogger{}
+		SetLogger(logger)
+		defer SetLogger(emptyLogger{})
+		c.debugJob(nil, "Counts", "main", countingStringer{&calls}, "limited", 2)
+		if fmt.Sprint(logger.logs) != "[Counts main=value limited=2]" {
+			t.Errorf("expected formatted log, got %v", logger.logs)
+		}
+	})
+}
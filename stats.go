@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"slices"
+	"time"
+)
+
+// defaultBuckets are the upper bounds of the latency histograms
+var defaultBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Stats is a snapshot of the controllers counters and gauges, see `Stats`
+type Stats struct {
+	Entries map[string]EntryStats // by entry point (i.e. "Go", "LimitedGo", "Background")
+	//-----Limiter------
+	Limit   int // limited jobs that can run at a time
+	InUse   int // limited jobs running
+	Waiting int // limited jobs waiting for the limiter
+	Queued  int // limited jobs waiting for a worker (see `WithWorkerPool`)
+	//-----Latency------
+	QueueWait Histogram // from being submitted (including any delay) to starting
+	RunTime   Histogram
+}
+
+// EntryStats are the counts for jobs submitted with an entry point
+type EntryStats struct {
+	Submitted int64
+	Started   int64
+	Succeeded int64
+	Failed    int64
+	Skipped   int64
+	Cancelled int64 // see `DelayedJob.Cancel`
+}
+
+// Active returns the number of jobs that were submitted but havent ended
+func (e EntryStats) Active() int64 {
+	return e.Submitted - e.Succeeded - e.Failed - e.Skipped - e.Cancelled
+}
+
+// Histogram counts durations into buckets. Counts[i] is the number that were
+// less than or equal to Bounds[i] (and greater than the bound before), the
+// last count is for everything greater than the last bound
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []int64
+	Count  int64
+	Sum    time.Duration
+}
+
+func newHistogram(bounds []time.Duration) Histogram {
+	return Histogram{Bounds: bounds, Counts: make([]int64, len(bounds)+1)}
+}
+
+func (h *Histogram) observe(d time.Duration) {
+	i, _ := slices.BinarySearch(h.Bounds, d)
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+}
+
+func (h Histogram) clone() Histogram {
+	h.Bounds = slices.Clone(h.Bounds)
+	h.Counts = slices.Clone(h.Counts)
+	return h
+}
+
+// Mean returns the average duration, 0 if nothing was observed
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// jobStats are kept by the job registry (with its lock held)
+type jobStats struct {
+	entries   map[string]*EntryStats
+	queueWait Histogram
+	runTime   Histogram
+}
+
+func newJobStats() jobStats {
+	return jobStats{
+		entries:   make(map[string]*EntryStats),
+		queueWait: newHistogram(defaultBuckets),
+		runTime:   newHistogram(defaultBuckets),
+	}
+}
+
+func (s *jobStats) entry(name string) *EntryStats {
+	e, ok := s.entries[name]
+	if !ok {
+		e = &EntryStats{}
+		s.entries[name] = e
+	}
+	return e
+}
+
+// Stats returns a snapshot of counts per entry point, the limiter usage and
+// latency histograms
+func (c *Controller) Stats() Stats {
+	stats := Stats{}
+	stats.Limit, stats.InUse, stats.Waiting = c.limiter.usage()
+	if c.pool != nil {
+		c.pool.mu.Lock()
+		stats.Queued = len(c.pool.jobs)
+		c.pool.mu.Unlock()
+	}
+
+	c.jobs.mu.Lock()
+	defer c.jobs.mu.Unlock()
+	stats.Entries = make(map[string]EntryStats, len(c.jobs.stats.entries))
+	for name, e := range c.jobs.stats.entries {
+		stats.Entries[name] = *e
+	}
+	stats.QueueWait = c.jobs.stats.queueWait.clone()
+	stats.RunTime = c.jobs.stats.runTime.clone()
+	return stats
+}
+
+// EntryNames returns the entry points in stats sorted by name
+func (s Stats) EntryNames() []string {
+	names := make([]string, 0, len(s.Entries))
+	for name := range s.Entries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("Counts jobs per entry point", func(t *testing.T) {
+		c, _ := NewController()
+		c.Go(newRunner(nil))
+		c.Go(newRunner(fmt.Errorf("failed")))
+		c.LimitedGo(newRunner(nil))
+		c.Background(foreverRunnner{})
+		c.Wait()
+		c.Go(newRunner(nil))
+
+		stats := c.Stats()
+		expected := map[string]EntryStats{
+			"Go":         {Submitted: 3, Started: 2, Succeeded: 1, Failed: 1, Skipped: 1},
+			"LimitedGo":  {Submitted: 1, Started: 1, Succeeded: 1},
+			"Background": {Submitted: 1, Started: 1, Succeeded: 1},
+		}
+		for name, e := range expected {
+			if stats.Entries[name] != e {
+				t.Errorf("expected %v to be %+v, got %+v", name, e, stats.Entries[name])
+			}
+		}
+		if names := stats.EntryNames(); !slices.Equal(names, []string{"Background", "Go", "LimitedGo"}) {
+			t.Errorf("expected sorted entry names, got %v", names)
+		}
+		if stats.RunTime.Count != 4 || stats.QueueWait.Count != 4 {
+			t.Errorf("expected 4 observations, got %v and %v", stats.RunTime.Count, stats.QueueWait.Count)
+		}
+	})
+	t.Run("Shows limiter usage and queue depth", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(1, WithWorkerPool(nil))
+		release := make(chan struct{})
+		started := make(chan struct{})
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			close(started)
+			<-release
+			return nil
+		}))
+		<-started
+		c.LimitedGo(newRunner(nil))
+		c.LimitedGo(newRunner(nil))
+
+		stats := c.Stats()
+		if stats.Limit != 1 || stats.InUse != 1 || stats.Queued != 2 {
+			t.Errorf("expected limit 1, 1 in use and 2 queued, got %+v", stats)
+		}
+		if active := stats.Entries["LimitedGo"].Active(); active != 3 {
+			t.Errorf("expected 3 active, got %v", active)
+		}
+		close(release)
+		c.Wait()
+	})
+	t.Run("Histogram buckets", func(t *testing.T) {
+		h := newHistogram([]time.Duration{time.Millisecond, time.Second})
+		h.observe(time.Millisecond)
+		h.observe(time.Minute)
+		h.observe(2 * time.Millisecond)
+		h.observe(time.Microsecond)
+		if !slices.Equal(h.Counts, []int64{2, 1, 1}) {
+			t.Errorf("expected [2 1 1], got %v", h.Counts)
+		}
+		if h.Mean() != h.Sum/4 {
+			t.Errorf("expected mean to be sum / count, got %v", h.Mean())
+		}
+	})
+}
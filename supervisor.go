@@ -0,0 +1,212 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+)
+
+var ErrTooManyRestarts = fmt.Errorf("too many restarts")
+
+// RestartStrategy is which runners a supervisor restarts when one fails
+type RestartStrategy int
+
+const (
+	// OneForOne only restarts the runner that failed
+	OneForOne RestartStrategy = iota
+	// OneForAll stops and restarts every runner
+	OneForAll
+	// RestForOne stops and restarts the runner that failed and every runner after it
+	RestForOne
+)
+
+// SupervisorConfig configures `Supervise`
+type SupervisorConfig struct {
+	Strategy RestartStrategy
+	// MaxRestarts is how many restarts are allowed within Window before the
+	// supervisor gives up and shuts down the controller, defaults to 3
+	MaxRestarts int
+	Window      time.Duration // defaults to 5s
+	// Backoff is how long to wait before restarting, doubled for every restart
+	// already in the window (up to MaxBackoff). 0 restarts right away
+	Backoff    time.Duration
+	MaxBackoff time.Duration // defaults to 30s
+}
+
+// Supervise runs the runners in the background (same as `Background`) but if
+// one returns an error it is restarted (based on the strategy) instead of
+// shutting everything down. Runners that return nil are not restarted. If
+// there are more than MaxRestarts in Window it returns ErrTooManyRestarts and
+// the controller is shutdown. Each runner gets its own `Child` controller so
+// it can be stopped by itself (named runners can still call `Ready` for
+// `WaitReady` and `Requires` on this controller)
+func (c *Controller) Supervise(config SupervisorConfig, runners ...Runner) {
+	if config.MaxRestarts < 1 {
+		config.MaxRestarts = 3
+	}
+	if config.Window <= 0 {
+		config.Window = 5 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	s := &supervisor{config: config, children: make([]*supervisedChild, len(runners))}
+	for i, r := range runners {
+		s.children[i] = &supervisedChild{runner: r}
+	}
+	c.Background(s, internalRunner())
+}
+
+type supervisor struct {
+	config   SupervisorConfig
+	children []*supervisedChild
+	rc       *Controller
+	exits    chan supervisedExit
+	pending  []supervisedExit // exits read while stopping other children
+	running  int
+}
+
+type supervisedChild struct {
+	runner Runner
+	ctrl   *Controller // nil if not running
+}
+
+type supervisedExit struct {
+	index int
+	err   error
+}
+
+func (s *supervisor) Run(rc *Controller) error {
+	s.rc = rc
+	s.exits = make(chan supervisedExit)
+	for i := range s.children {
+		s.start(i)
+	}
+
+	restarts := []time.Time{}
+	for s.running > 0 {
+		e := s.next()
+		if e.err == nil || rc.IsShuttingDown() {
+			continue
+		}
+		log.Warnf("Supervised runner %v failed: %v", e.index, e.err)
+
+		now := time.Now()
+		inWindow := restarts[:0]
+		for _, t := range restarts {
+			if now.Sub(t) < s.config.Window {
+				inWindow = append(inWindow, t)
+			}
+		}
+		restarts = append(inWindow, now)
+		if len(restarts) > s.config.MaxRestarts {
+			s.stop(s.all())
+			return fmt.Errorf("%w: %v", ErrTooManyRestarts, errorSummary(e.err))
+		}
+
+		toRestart := s.restartSet(e.index)
+		s.stop(toRestart)
+		if !s.wait(len(restarts)) {
+			continue
+		}
+		for _, i := range toRestart {
+			log.Debugf("Restarting supervised runner %v", i)
+			s.start(i)
+		}
+	}
+	return nil
+}
+
+// start runs child i in a new child controller
+func (s *supervisor) start(i int) {
+	ctrl, err := s.rc.child(s.rc.backCountChan, nil)
+	if err != nil {
+		return // shutting down
+	}
+	s.children[i].ctrl = ctrl
+	s.running++
+
+	var runErr error
+	ctrl.goJob(ctrl.newJob("Supervise", s.children[i].runner, []JobOption{reportErrors(func(err error) {
+		runErr = err
+	})}))
+	go func() {
+		ctrl.Wait()
+		s.exits <- supervisedExit{index: i, err: runErr}
+	}()
+}
+
+// next returns the next child to exit
+func (s *supervisor) next() supervisedExit {
+	if len(s.pending) > 0 {
+		e := s.pending[0]
+		s.pending = s.pending[1:]
+		return e
+	}
+	e := <-s.exits
+	s.children[e.index].ctrl = nil
+	s.running--
+	return e
+}
+
+// stop shuts down the running children in indexes and waits for them to exit
+func (s *supervisor) stop(indexes []int) {
+	stopping := map[int]bool{}
+	for _, i := range indexes {
+		if ctrl := s.children[i].ctrl; ctrl != nil {
+			ctrl.Shutdown()
+			stopping[i] = true
+		}
+	}
+	for len(stopping) > 0 {
+		e := <-s.exits
+		s.children[e.index].ctrl = nil
+		s.running--
+		if stopping[e.index] {
+			delete(stopping, e.index)
+		} else {
+			s.pending = append(s.pending, e)
+		}
+	}
+}
+
+func (s *supervisor) all() []int {
+	indexes := make([]int, len(s.children))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// restartSet returns the children to restart when i fails
+func (s *supervisor) restartSet(i int) []int {
+	switch s.config.Strategy {
+	case OneForAll:
+		return s.all()
+	case RestForOne:
+		return s.all()[i:]
+	default:
+		return []int{i}
+	}
+}
+
+// wait waits the backoff for the number of restarts. Returns false if
+// shutting down before
+func (s *supervisor) wait(restarts int) bool {
+	backoff := s.config.Backoff
+	for i := 1; i < restarts && backoff < s.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, s.config.MaxBackoff)
+	if backoff <= 0 {
+		return !s.rc.IsShuttingDown()
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-s.rc.ShuttingDownChan():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,148 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyRunner fails the first `failures` times it is ran, then runs until shutdown
+type flakyRunner struct {
+	mu       sync.Mutex
+	failures int
+	starts   int
+	started  chan int
+}
+
+func newFlakyRunner(failures int) *flakyRunner {
+	return &flakyRunner{failures: failures, started: make(chan int, 100)}
+}
+
+func (f *flakyRunner) Run(rc *Controller) error {
+	f.mu.Lock()
+	f.starts++
+	starts := f.starts
+	f.mu.Unlock()
+	f.started <- starts
+	if starts <= f.failures {
+		return fmt.Errorf("dropped connection")
+	}
+	<-rc.ShuttingDownChan()
+	return nil
+}
+
+func (f *flakyRunner) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.starts
+}
+
+// readyRunner is named name and is ready right away, then runs until shutdown
+type readyRunner struct{ name string }
+
+func (r readyRunner) Name() string { return r.name }
+func (r readyRunner) Run(rc *Controller) error {
+	rc.Ready()
+	<-rc.ShuttingDownChan()
+	return nil
+}
+
+func TestSupervisor(t *testing.T) {
+	t.Run("One for one only restarts the failed runner", func(t *testing.T) {
+		c, _ := NewController()
+		flaky := newFlakyRunner(2)
+		stable := newFlakyRunner(0)
+		c.Supervise(SupervisorConfig{Strategy: OneForOne}, flaky, stable)
+
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			for <-flaky.started < 3 {
+			}
+			return nil
+		}))
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+		if flaky.count() != 3 || stable.count() != 1 {
+			t.Errorf("expected 3 and 1 starts, got %v and %v", flaky.count(), stable.count())
+		}
+	})
+	t.Run("One for all restarts every runner", func(t *testing.T) {
+		c, _ := NewController()
+		first := newFlakyRunner(0)
+		flaky := newFlakyRunner(1)
+		last := newFlakyRunner(0)
+		c.Supervise(SupervisorConfig{Strategy: OneForAll}, first, flaky, last)
+
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			for <-first.started < 2 {
+			}
+			for <-last.started < 2 {
+			}
+			return nil
+		}))
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+		if first.count() != 2 || flaky.count() != 2 || last.count() != 2 {
+			t.Errorf("expected 2 starts each, got %v, %v and %v", first.count(), flaky.count(), last.count())
+		}
+	})
+	t.Run("Rest for one restarts runners after the failed one", func(t *testing.T) {
+		c, _ := NewController()
+		first := newFlakyRunner(0)
+		flaky := newFlakyRunner(1)
+		last := newFlakyRunner(0)
+		c.Supervise(SupervisorConfig{Strategy: RestForOne}, first, flaky, last)
+
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			for <-last.started < 2 {
+			}
+			for <-flaky.started < 2 {
+			}
+			return nil
+		}))
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+		if first.count() != 1 || flaky.count() != 2 || last.count() != 2 {
+			t.Errorf("expected 1, 2 and 2 starts, got %v, %v and %v", first.count(), flaky.count(), last.count())
+		}
+	})
+	t.Run("Shuts down after too many restarts", func(t *testing.T) {
+		c, _ := NewController()
+		flaky := newFlakyRunner(100)
+		stable := newFlakyRunner(0)
+		c.Supervise(SupervisorConfig{MaxRestarts: 2}, flaky, stable)
+		c.Go(foreverRunnner{})
+
+		if err := c.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if c.Errors() != "too many restarts: dropped connection" {
+			t.Errorf("expected too many restarts error, got %v", c.Errors())
+		}
+		if flaky.count() != 3 {
+			t.Errorf("expected 3 starts, got %v", flaky.count())
+		}
+	})
+	t.Run("Supervised runners can be waited on by the parent", func(t *testing.T) {
+		c, _ := NewController(WithReadyTimeout(time.Second))
+		c.Supervise(SupervisorConfig{}, readyRunner{name: "db"})
+
+		if err := c.WaitReady("db"); err != nil {
+			t.Errorf("expected db to be ready, got %v", err)
+		}
+		entry := ""
+		for _, job := range c.Jobs() {
+			if job.Name == "db" {
+				entry = job.Entry
+			}
+		}
+		if entry != "Supervise" {
+			t.Errorf("expected db to be a Supervise job, got %q", entry)
+		}
+		c.Shutdown()
+		c.Wait()
+	})
+}
@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Tracer is called as jobs move through the controller, see `WithTracer`.
+// It is called from the jobs go routines so has to be safe to use concurrently
+type Tracer interface {
+	JobEnqueued(job JobSnapshot) // submitted, Parent is set if submitted through a jobs `rc`
+	JobStarted(job JobSnapshot)
+	JobEnded(job JobSnapshot) // ran or was skipped (see State)
+}
+
+// WithTracer calls tracer as jobs are submitted, start and end
+func WithTracer(tracer Tracer) Option {
+	return func(c *Controller) error {
+		c.jobs.tracer = tracer
+		return nil
+	}
+}
+
+// ChromeTracer is a Tracer that writes the Chrome Trace Event format, which
+// can be opened with Perfetto or chrome://tracing. Each job gets its own row
+// showing how long it waited (i.e. for the limiter) and how long it ran, with
+// arrows from the jobs that submitted it
+type ChromeTracer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	start   time.Time
+	written bool
+	err     error // first error writing
+}
+
+// NewChromeTracer returns a ChromeTracer that writes to w, `Close` has to be
+// called once the controller is finished
+func NewChromeTracer(w io.Writer) *ChromeTracer {
+	return &ChromeTracer{w: w, start: time.Now()}
+}
+
+// traceEvent is a single event in the Chrome Trace Event format
+type traceEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"cat,omitempty"`
+	Phase     string         `json:"ph"`
+	Timestamp float64        `json:"ts"` // microseconds
+	Duration  float64        `json:"dur,omitempty"`
+	Pid       int            `json:"pid"`
+	Tid       uint64         `json:"tid"`
+	ID        uint64         `json:"id,omitempty"`
+	BindPoint string         `json:"bp,omitempty"`
+	Args      map[string]any `json:"args,omitempty"`
+}
+
+func (t *ChromeTracer) JobEnqueued(job JobSnapshot) {
+	events := []traceEvent{{
+		Name:  "thread_name",
+		Phase: "M",
+		Tid:   job.ID,
+		Args:  map[string]any{"name": traceName(job)},
+	}}
+	if job.Parent != 0 {
+		ts := t.timestamp(job.Enqueued)
+		events = append(events,
+			traceEvent{Name: "submit", Category: "submit", Phase: "s", Timestamp: ts, Tid: job.Parent, ID: job.ID},
+			traceEvent{Name: "submit", Category: "submit", Phase: "f", Timestamp: ts, Tid: job.ID, ID: job.ID, BindPoint: "e"},
+		)
+	}
+	t.write(events...)
+}
+
+func (t *ChromeTracer) JobStarted(job JobSnapshot) {
+	t.write(t.span(job, "waiting", job.Enqueued, job.Started))
+}
+
+func (t *ChromeTracer) JobEnded(job JobSnapshot) {
+	if job.Started.IsZero() {
+		t.write(t.span(job, job.State.String(), job.Enqueued, time.Now())) // skipped or cancelled
+		return
+	}
+	event := t.span(job, "running", job.Started, job.Ended)
+	event.Args["state"] = job.State.String()
+	if job.Err != nil {
+		event.Args["error"] = job.Err.Error()
+	}
+	t.write(event)
+}
+
+// Close finishes the trace, returns the first error writing it
+func (t *ChromeTracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.written {
+		t.writeString("[")
+	}
+	t.writeString("]\n")
+	return t.err
+}
+
+// span returns a complete event for job from start to end
+func (t *ChromeTracer) span(job JobSnapshot, category string, start, end time.Time) traceEvent {
+	return traceEvent{
+		Name:      traceName(job),
+		Category:  category,
+		Phase:     "X",
+		Timestamp: t.timestamp(start),
+		Duration:  float64(end.Sub(start).Nanoseconds()) / 1000,
+		Tid:       job.ID,
+		Args:      map[string]any{"entry": job.Entry},
+	}
+}
+
+func (t *ChromeTracer) timestamp(at time.Time) float64 {
+	return float64(at.Sub(t.start).Nanoseconds()) / 1000
+}
+
+func (t *ChromeTracer) write(events ...traceEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, event := range events {
+		b, err := json.Marshal(event)
+		if err != nil {
+			t.setErr(err)
+			continue
+		}
+		if t.written {
+			t.writeString(",\n")
+		} else {
+			t.writeString("[\n")
+			t.written = true
+		}
+		t.writeString(string(b))
+	}
+}
+
+// writeString should be called with the lock held
+func (t *ChromeTracer) writeString(s string) {
+	if t.err != nil {
+		return
+	}
+	_, err := io.WriteString(t.w, s)
+	t.setErr(err)
+}
+
+func (t *ChromeTracer) setErr(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+func traceName(job JobSnapshot) string {
+	if job.Name == "" {
+		return fmt.Sprintf("job %v", job.ID)
+	}
+	return job.Name
+}
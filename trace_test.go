@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// recordingTracer keeps every call
+type recordingTracer struct {
+	mu     sync.Mutex
+	events []string
+	jobs   map[string]JobSnapshot
+}
+
+func (r *recordingTracer) record(event string, job JobSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event+" "+job.Name)
+	r.jobs[job.Name] = job
+}
+
+func (r *recordingTracer) JobEnqueued(job JobSnapshot) { r.record("enqueued", job) }
+func (r *recordingTracer) JobStarted(job JobSnapshot)  { r.record("started", job) }
+func (r *recordingTracer) JobEnded(job JobSnapshot)    { r.record("ended", job) }
+
+func TestTracer(t *testing.T) {
+	t.Run("Calls the tracer with parents", func(t *testing.T) {
+		tracer := &recordingTracer{jobs: map[string]JobSnapshot{}}
+		c, _ := NewController(WithTracer(tracer))
+		c.BGo(RunnerFunc(func(rc *Controller) error {
+			rc.BGo(newRunner(nil), Name("child"))
+			return nil
+		}), Name("parent"))
+		c.Wait()
+		c.Go(newRunner(nil), Name("skipped"))
+
+		expected := []string{"enqueued parent", "started parent", "enqueued child", "started child", "ended child", "ended parent", "enqueued skipped", "ended skipped"}
+		if fmt.Sprint(tracer.events) != fmt.Sprint(expected) {
+			t.Errorf("expected %v, got %v", expected, tracer.events)
+		}
+		if tracer.jobs["child"].Parent != tracer.jobs["parent"].ID {
+			t.Errorf("expected child parent to be %v, got %v", tracer.jobs["parent"].ID, tracer.jobs["child"].Parent)
+		}
+		if tracer.jobs["parent"].Parent != 0 {
+			t.Errorf("expected parent to not have a parent")
+		}
+		if tracer.jobs["skipped"].State != JobSkipped {
+			t.Errorf("expected skipped, got %v", tracer.jobs["skipped"].State)
+		}
+	})
+	t.Run("Writes chrome trace events", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		tracer := NewChromeTracer(buf)
+		c, _ := NewController(WithTracer(tracer))
+		c.Go(RunnerFunc(func(rc *Controller) error {
+			rc.LimitedGo(newRunner(fmt.Errorf("failed")), Name("child"))
+			return nil
+		}), Name("parent"))
+		c.Wait()
+		if err := tracer.Close(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+
+		events := []traceEvent{}
+		if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+			t.Fatalf("expected valid json, got %v\n%v", err, buf.String())
+		}
+		found := map[string]traceEvent{}
+		for _, e := range events {
+			found[e.Phase+" "+e.Category+" "+e.Name] = e
+		}
+		for _, key := range []string{"M  thread_name", "X waiting parent", "X running parent", "X waiting child", "X running child", "s submit submit", "f submit submit"} {
+			if _, ok := found[key]; !ok {
+				t.Errorf("expected %v event in %v", key, buf.String())
+			}
+		}
+		if found["s submit submit"].Tid != found["X running parent"].Tid || found["f submit submit"].Tid != found["X running child"].Tid {
+			t.Errorf("expected flow from parent to child")
+		}
+		if found["X running child"].Args["error"] != "failed" {
+			t.Errorf("expected error arg, got %v", found["X running child"].Args)
+		}
+	})
+	t.Run("Empty trace is valid", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		NewChromeTracer(buf).Close()
+		if buf.String() != "[]\n" {
+			t.Errorf("expected empty array, got %q", buf.String())
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"runtime/pprof"
+	"sync"
+)
+
+// WithWorkerPool runs `LimitedGo` and `BlLimitedGo` jobs on exactly `limit`
+// long lived workers instead of starting a go routine for each job. init is
+// called once by each worker before it starts pulling jobs (can be nil)
+func WithWorkerPool(init WorkerInit) Option {
+	return func(c *Controller) error {
+		c.pool = newWorkQueue()
+		c.workerInit = init
+		return nil
+	}
+}
+
+// WorkerInit is called by each worker when it starts. The value returned is
+// available to every job the worker runs with `WorkerState`, if it implements
+// `io.Closer` it will be closed when the worker exits
+type WorkerInit func(worker int) (any, error)
+
+type worker struct {
+	index int
+	state any
+}
+
+// Worker returns the index of the pool worker running the job. ok is false if
+// the job was not ran by a worker (i.e. `Go` or `WithWorkerPool` not used)
+func (c *Controller) Worker() (index int, ok bool) {
+	if c.worker == nil {
+		return -1, false
+	}
+	return c.worker.index, true
+}
+
+// WorkerState returns the value returned by the `WorkerInit` for the worker
+// running the job, nil if not ran by a worker
+func (c *Controller) WorkerState() any {
+	if c.worker == nil {
+		return nil
+	}
+	return c.worker.state
+}
+
+// queuedJob is a limited job waiting for a worker
+type queuedJob struct {
+	*job
+	finished func()
+	started  chan struct{} // closed when a worker has a slot for it, nil if no one cares
+}
+
+// pickedUp is called once a worker has the job and a slot for it (or it was
+// skipped)
+func (q *queuedJob) pickedUp() {
+	if q.started != nil {
+		close(q.started)
+	}
+}
+
+func (q *queuedJob) run(c *Controller, w *worker) {
+	defer q.finished()
+	c.runStarted(q.job, w)
+}
+
+// workQueue is an unbounded FIFO queue the workers pull from
+type workQueue struct {
+	mu     sync.Mutex
+	jobs   []*queuedJob
+	closed bool
+	signal chan struct{} // notifies a worker there is something in the queue
+}
+
+func newWorkQueue() *workQueue {
+	return &workQueue{signal: make(chan struct{}, 1)}
+}
+
+// push adds the job to the queue, returns false if the queue was already closed
+func (q *workQueue) push(j *queuedJob) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false
+	}
+	q.jobs = append(q.jobs, j)
+	q.notify()
+	return true
+}
+
+// pop waits for the next job. If done is closed before, it returns false
+func (q *workQueue) pop(done <-chan struct{}) (*queuedJob, bool) {
+	for {
+		select {
+		case <-done:
+			return nil, false
+		default:
+		}
+
+		q.mu.Lock()
+		if len(q.jobs) > 0 {
+			j := q.jobs[0]
+			q.jobs[0] = nil
+			q.jobs = q.jobs[1:]
+			if len(q.jobs) > 0 {
+				q.notify() // wake up another worker for the rest
+			}
+			q.mu.Unlock()
+			return j, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-done:
+			return nil, false
+		case <-q.signal:
+		}
+	}
+}
+
+// close stops accepting jobs and returns everything that was still queued
+func (q *workQueue) close() []*queuedJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	jobs := q.jobs
+	q.jobs = nil
+	return jobs
+}
+
+// notify should be called with the lock held
+func (q *workQueue) notify() {
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// startWorkers starts the pool workers, they are counted as background jobs
+// so they dont keep the controller open
+func (c *Controller) startWorkers(limit int) {
+	for i := 0; i < limit; i++ {
+		c.addCount(c.backCountChan, nil, func(finished func()) {
+			go c.withLabels(nil, func() {
+				c.runWorker(i, finished)
+			})
+		})
+	}
+}
+
+func (c *Controller) runWorker(index int, finished func()) {
+	defer finished()
+	w := &worker{index: index}
+	if c.workerInit != nil {
+		state, err := c.workerInit(index)
+		if err != nil {
+			c.errorChan <- err
+			c.Shutdown()
+			c.skipQueued()
+			return
+		}
+		w.state = state
+		if closer, ok := state.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
+	ctx := pprof.WithLabels(context.Background(), c.labels(nil)) // so the labels go back to the workers after each job
+
+	// jobs wait to start (i.e. for the rate limit) before a slot is taken, and
+	// workers still go through the limiter so it can be lowered (i.e.
+	// `WithAdaptiveLimit`)
+	for {
+		j, ok := c.pool.pop(c.doneChan)
+		if !ok {
+			break
+		}
+		if !c.waitForStart(j.job) {
+			j.pickedUp()
+			j.finished()
+			continue
+		}
+		if !c.limiter.acquire(c.doneChan) {
+			log.Debugf("Not running queued %v because shuting down", j.job)
+			c.skipJob(j.job)
+			j.pickedUp()
+			j.finished()
+			break
+		}
+		j.pickedUp() // only once it has a slot so `BlLimitedGo` blocks until then
+		pprof.Do(ctx, c.labels(j.job), func(context.Context) {
+			j.run(c, w)
+		})
+		c.releaseLimiter(j.job)
+	}
+	c.skipQueued()
+}
+
+// skipQueued finishes all of the jobs still in the queue without running them
+func (c *Controller) skipQueued() {
+	for _, j := range c.pool.close() {
+		log.Debugf("Not running queued %v because shuting down", j.job)
+		c.skipJob(j.job)
+		j.finished()
+	}
+}
+
+// queueJob adds the job to the worker pool queue, finished is called once it
+// ran or was skipped. started is closed when a worker has a slot for it (can
+// be nil). Returns false if it was skipped because the controller is shutting down
+func (c *Controller) queueJob(j *job, finished func(), started chan struct{}) bool {
+	if !c.pool.push(&queuedJob{job: j, finished: finished, started: started}) {
+		log.Debugf("Not running limited %v because shuting down", j)
+		c.skipJob(j)
+		finished()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type closeCounter struct {
+	mu     sync.Mutex
+	closed int
+}
+
+func (cc *closeCounter) Close() error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.closed++
+	return nil
+}
+
+func TestWorkerPool(t *testing.T) {
+	t.Run("Runs limited jobs on the workers", func(t *testing.T) {
+		cc := &closeCounter{}
+		inits := make(chan int, 3)
+		c, err := NewControllerWithLimit(3, WithWorkerPool(func(worker int) (any, error) {
+			inits <- worker
+			return cc, nil
+		}))
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+
+		mu := sync.Mutex{}
+		workers := map[int]int{}
+		for i := 0; i < 30; i++ {
+			job := RunnerFunc(func(rc *Controller) error {
+				index, ok := rc.Worker()
+				if !ok {
+					return fmt.Errorf("expected job to be ran by a worker")
+				}
+				if rc.WorkerState() != cc {
+					return fmt.Errorf("expected worker state to be from init")
+				}
+				mu.Lock()
+				workers[index]++
+				mu.Unlock()
+				return nil
+			})
+			if i%2 == 0 {
+				c.LimitedGo(job)
+			} else {
+				c.BlLimitedGo(job)
+			}
+		}
+
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+		if len(inits) != 3 {
+			t.Errorf("expected 3 workers to start, got %v", len(inits))
+		}
+		total := 0
+		for index, count := range workers {
+			if index < 0 || index > 2 {
+				t.Errorf("expected worker index between 0 and 2, got %v", index)
+			}
+			total += count
+		}
+		if total != 30 {
+			t.Errorf("expected 30 jobs to run, got %v", total)
+		}
+		if cc.closed != 3 {
+			t.Errorf("expected worker state to be closed 3 times, got %v", cc.closed)
+		}
+	})
+	t.Run("Go jobs are not ran by a worker", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(1, WithWorkerPool(nil))
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			rc.Go(RunnerFunc(func(rc *Controller) error {
+				if _, ok := rc.Worker(); ok {
+					return fmt.Errorf("expected Go job to not be ran by a worker")
+				}
+				return nil
+			}))
+			return nil
+		}))
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+	})
+	t.Run("Skips queued jobs when shutting down", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(1, WithWorkerPool(nil))
+		rec := newRecieverRunnner(4)
+		c.LimitedGo(rec)
+		rec.started()
+
+		ran := false
+		c.LimitedGo(RunnerFunc(func(rc *Controller) error {
+			ran = true
+			return nil
+		}))
+		c.Shutdown()
+		if err := c.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if ran {
+			t.Errorf("expected queued job to be skipped")
+		}
+	})
+	t.Run("Shuts down if a worker fails to start", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(2, WithWorkerPool(func(worker int) (any, error) {
+			return nil, fmt.Errorf("no connection")
+		}))
+		c.Go(foreverRunnner{})
+		if err := c.Wait(); err != ErrErrors {
+			t.Errorf("expected ErrErrors, got %v", err)
+		}
+		if errStr := c.Errors(); errStr != "no connection, no connection" {
+			t.Errorf("expected errors to be 'no connection, no connection', got %v", errStr)
+		}
+	})
+	t.Run("BlLimitedGo blocks until a worker has a slot", func(t *testing.T) {
+		c, _ := NewControllerWithLimit(1, WithWorkerPool(nil))
+		c.Pause()
+		submitted := make(chan struct{})
+		go func() {
+			defer close(submitted)
+			c.BlLimitedGo(newRunner(nil))
+		}()
+		select {
+		case <-submitted:
+			t.Errorf("expected BlLimitedGo to block while paused")
+		case <-time.After(50 * time.Millisecond):
+		}
+		c.Resume()
+		<-submitted
+		if err := c.Wait(); err != nil {
+			t.Errorf("expected no errors, got %v (%v)", err, c.Errors())
+		}
+	})
+}